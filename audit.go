@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+var details string
+
+var auditChecks = []urna.VerificationResultType{
+	urna.Hash,
+	urna.Signature,
+	urna.Coverage,
+	urna.Certificate,
+	urna.Payload,
+	urna.Consistency,
+}
+
+func Audit() {
+	var audits []urna.AuditoriaSecao
+
+	forEachFile(expandDirs(auditFlags()), func(f string) {
+		var a urna.AuditoriaSecao
+		var err error
+		switch {
+		case strings.HasSuffix(f, ".zip"):
+			a, err = urna.AuditZip(f)
+		case strings.HasSuffix(f, ".vscmr"):
+			a, err = urna.AuditVscmr(f)
+		default:
+			return
+		}
+
+		if err != nil {
+			inputError(err)
+		}
+
+		trackResults(a.Results)
+		audits = append(audits, a)
+	})
+
+	header := []string{
+		"Arquivo",
+		"Municipio",
+		"Zona",
+		"Secao",
+		"Status"}
+	for _, t := range auditChecks {
+		header = append(header, t.String())
+	}
+	header = append(header, "Falhas", "Nao verificaveis")
+	w := newRecordWriter("csv", append(header, localHeader()...))
+
+	for _, a := range audits {
+		var failed, unverifiable int
+		for _, r := range a.Results {
+			switch r.Ok {
+			case urna.Nok:
+				failed++
+				log.Println(r.Msg(), r.Err)
+			case urna.Unverifiable:
+				unverifiable++
+			}
+		}
+
+		row := []string{
+			a.Filename,
+			a.Municipio,
+			a.Zona,
+			a.Secao,
+			a.Status.String()}
+
+		status := a.StatusByType()
+		for _, t := range auditChecks {
+			s, ok := status[t]
+			if ok {
+				row = append(row, s.String())
+			} else {
+				row = append(row, "")
+			}
+		}
+
+		local := localForAuditoria(a)
+		row = append(row, fmt.Sprint(failed), fmt.Sprint(unverifiable))
+		w.Write(auditRecord{a, local}, append(row, local.row()...))
+	}
+
+	w.Close()
+
+	if len(details) > 0 {
+		writeAuditDetails(audits)
+	}
+}
+
+// Replaces directories by the `*.zip` and `*.vscmr` files they contain, so
+// that filters apply to each section.
+func expandDirs(files []string) []string {
+	var expanded []string
+	for _, f := range files {
+		entries, err := os.ReadDir(f)
+		if err != nil {
+			expanded = append(expanded, f)
+			continue
+		}
+
+		for _, e := range entries {
+			if !e.IsDir() && (strings.HasSuffix(e.Name(), ".zip") || strings.HasSuffix(e.Name(), ".vscmr")) {
+				expanded = append(expanded, filepath.Join(f, e.Name()))
+			}
+		}
+	}
+
+	return expanded
+}
+
+func writeAuditDetails(audits []urna.AuditoriaSecao) {
+	f, err := os.Create(details)
+	if err != nil {
+		inputError(err)
+		return
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write(
+		[]string{
+			"Secao (arquivo)",
+			"Municipio",
+			"Zona",
+			"Secao",
+			"Arquivo",
+			"Tipo",
+			"Status",
+			"Erro",
+			"Motivo"})
+
+	for _, a := range audits {
+		for _, r := range a.Results {
+			var e string
+			if r.Err != nil {
+				e = r.Err.Error()
+			}
+
+			w.Write([]string{
+				a.Filename,
+				r.Municipio,
+				r.Zona,
+				r.Secao,
+				r.Filename,
+				r.Type.String(),
+				r.Ok.String(),
+				e,
+				r.Reason})
+		}
+	}
+
+	w.Flush()
+}
+
+func auditFlags() []string {
+	auditFlags := flag.NewFlagSet("audit", flag.ContinueOnError)
+	auditFlags.StringVar(&details, "details", "", "Write the per-check breakdown as CSV to this file")
+
+	err := auditFlags.Parse(os.Args[2:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(auditFlags.Args()) == 0 {
+		fmt.Println("usage: urna audit [-details <file>] <zip|dir|vscmr> ...")
+		auditFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return auditFlags.Args()
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
-	"encoding/csv"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	urna "github.com/mpbertram/urna/ue"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 func Bu() {
@@ -24,66 +27,238 @@ func Bu() {
 		verifyBu(verifyBuFlags())
 	case "csv":
 		buToCsv(csvBuFlags())
+	case "diff":
+		diffBu(diffBuFlags())
+	case "locais":
+		buByLocal(locaisBuFlags())
+	case "geo":
+		buToGeo(geoBuFlags())
+	case "urnas":
+		listUrnas(urnasBuFlags())
 	default:
-		fmt.Println("usage: urna bu <count|verify|csv> <options>")
-		fmt.Printf("provided function '%s' is none of (count, verify, csv)\n", function)
+		fmt.Println("usage: urna bu <count|verify|csv|diff|locais|geo|urnas> <options>")
+		fmt.Printf("provided function '%s' is none of (count, verify, csv, diff, locais, geo, urnas)\n", function)
+		setExitCode(exitInputError)
 	}
 }
 
+// One `bu csv` record: a section and the votes of the requested candidatos.
+type buCsvRecord struct {
+	Uf              string         `json:"uf"`
+	Municipio       string         `json:"municipio"`
+	TipoUrna        string         `json:"tipoUrna"`
+	TipoArquivo     string         `json:"tipoArquivo"`
+	ApuracaoTipo    string         `json:"apuracaoTipo"`
+	ApuracaoMotivo  string         `json:"apuracaoMotivo"`
+	Zona            int            `json:"zona"`
+	Local           int            `json:"local"`
+	Secao           int            `json:"secao"`
+	Cargo           string         `json:"cargo"`
+	Votos           map[string]int `json:"votos"`
+	candidatosOrder []string
+	localRecord
+}
+
+func (r buCsvRecord) row() []string {
+	row := []string{
+		r.Uf,
+		r.Municipio,
+		r.TipoUrna,
+		r.TipoArquivo,
+		r.ApuracaoTipo,
+		r.ApuracaoMotivo,
+		fmt.Sprint(r.Zona),
+		fmt.Sprint(r.Local),
+		fmt.Sprint(r.Secao)}
+	row = append(row, r.localRecord.row()...)
+
+	for _, candidato := range r.candidatosOrder {
+		row = append(row, fmt.Sprint(r.Votos[candidato]))
+	}
+
+	return row
+}
+
 func buToCsv(files []string) {
+	if long {
+		buToLongCsv(files)
+		return
+	}
+
 	cargo := urna.CargoConstitucionalFromString(cargo)
 	candidatos := splitCandidatosIntoSlice()
 
-	w := csv.NewWriter(os.Stdout)
-	w.Write(
-		append(
-			[]string{
-				"UF",
-				"Municipio",
-				"Tipo urna",
-				"Tipo arquivo",
-				"Apuracao (tipo)",
-				"Apuracao (motivo)",
-				"Zona",
-				"Local",
-				"Secao"}, candidatos...))
-
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+	header := []string{
+		"UF",
+		"Municipio",
+		"Tipo urna",
+		"Tipo arquivo",
+		"Apuracao (tipo)",
+		"Apuracao (motivo)",
+		"Zona",
+		"Local",
+		"Secao"}
+	header = append(header, localHeader()...)
+
+	if len(candidatos) == 0 {
+		// Columns are discovered from the data, so rows are written at the end.
+		var records []buCsvRecord
+		found := make(map[string]bool)
+		forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+			r := countVotos(bu, cargo, nil)
+			for candidato := range r.Votos {
+				found[candidato] = true
+			}
+			records = append(records, r)
+		})
+
+		candidatos = sortCandidatos(maps.Keys(found))
+		w := newRecordWriter("csv", append(header, candidatos...))
+		for _, r := range records {
+			r.candidatosOrder = candidatos
+			w.Write(r, r.row())
+		}
+		w.Close()
 
-		if strings.HasSuffix(f, ".zip") {
-			urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
-				bu, err := eeg.ReadBu()
-				if err != nil {
-					log.Fatal(err)
-				}
+		return
+	}
 
-				w.Write(countVotos(bu, cargo, candidatos))
-				w.Flush()
+	w := newRecordWriter("csv", append(header, candidatos...))
 
-				return nil
-			})
-		}
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		r := countVotos(bu, cargo, candidatos)
+		w.Write(r, r.row())
+		w.Flush()
+	})
 
-		if strings.HasSuffix(f, ".bu") {
-			entry := urna.BuEntry{Path: f}
-			bu, err := entry.ReadBu()
-			if err != nil {
-				log.Fatal(err)
+	w.Close()
+}
+
+// One long `bu csv` record: the votes of one votável for one cargo of a section.
+type buLongRecord struct {
+	Uf         string `json:"uf"`
+	Municipio  string `json:"municipio"`
+	Zona       int    `json:"zona"`
+	Local      int    `json:"local"`
+	Secao      int    `json:"secao"`
+	IdEleicao  int    `json:"idEleicao"`
+	Cargo      string `json:"cargo"`
+	TipoVoto   string `json:"tipoVoto"`
+	Partido    int    `json:"partido,omitempty"` // Empty for Branco and Nulo.
+	Votavel    int    `json:"votavel,omitempty"` // Empty for Branco and Nulo.
+	Quantidade int    `json:"quantidade"`
+	localRecord
+}
+
+func buToLongCsv(files []string) {
+	var cargos []string
+	if len(cargo) > 0 {
+		cargos = splitIntoSlice(cargo)
+	}
+
+	w := newRecordWriter("csv", append([]string{
+		"UF",
+		"Municipio",
+		"Zona",
+		"Local",
+		"Secao",
+		"ID eleicao",
+		"Cargo",
+		"Tipo voto",
+		"Partido",
+		"Votavel",
+		"Quantidade"}, localHeader()...))
+
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		for _, r := range buLongRecords(bu) {
+			if len(cargos) > 0 && !slices.ContainsFunc(cargos, func(c string) bool { return strings.EqualFold(c, r.Cargo) }) {
+				continue
 			}
 
-			w.Write(countVotos(bu, cargo, candidatos))
+			w.Write(r, append([]string{
+				r.Uf,
+				r.Municipio,
+				fmt.Sprint(r.Zona),
+				fmt.Sprint(r.Local),
+				fmt.Sprint(r.Secao),
+				fmt.Sprint(r.IdEleicao),
+				r.Cargo,
+				r.TipoVoto,
+				blankIfZero(r.Partido),
+				blankIfZero(r.Votavel),
+				fmt.Sprint(r.Quantidade)}, r.localRecord.row()...))
 		}
+		w.Flush()
+	})
+
+	w.Close()
+}
+
+// One record per BU tuple, in file order.
+func buLongRecords(bu urna.EntidadeBoletimUrna) []buLongRecord {
+	id := bu.IdentificacaoSecao
+	m := bu.Municipio()
+	local := localFor(id)
+
+	var records []buLongRecord
+	for _, v := range urna.ListVotosBu(bu) {
+		records = append(records, buLongRecord{
+			Uf:          m.Uf,
+			Municipio:   m.Nome,
+			Zona:        int(id.MunicipioZona.Zona),
+			Local:       int(id.Local),
+			Secao:       int(id.Secao),
+			IdEleicao:   int(v.IdEleicao),
+			Cargo:       v.Cargo,
+			TipoVoto:    v.TipoVoto.String(),
+			Partido:     int(v.Partido),
+			Votavel:     int(v.Codigo),
+			Quantidade:  v.Quantidade,
+			localRecord: local,
+		})
 	}
 
-	w.Flush()
+	return records
 }
 
-func countVotos(bu urna.EntidadeBoletimUrna, cargo urna.CargoConstitucional, candidatos []string) []string {
+func blankIfZero(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	return fmt.Sprint(n)
+}
+
+// Orders votável numbers numerically, followed by Branco and Nulo.
+func sortCandidatos(candidatos []string) []string {
+	slices.SortFunc(candidatos, func(a, b string) int {
+		na, errA := strconv.Atoi(a)
+		nb, errB := strconv.Atoi(b)
+		switch {
+		case errA == nil && errB == nil:
+			return na - nb
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+
+	return candidatos
+}
+
+// Counts the votes of `candidatos` for `cargo`; all candidatos found if nil.
+func countVotos(bu urna.EntidadeBoletimUrna, cargo urna.CargoConstitucional, candidatos []string) buCsvRecord {
 	votos := urna.CountVotosBu(bu, []urna.CargoConstitucional{cargo})
-	var votosForCandidato []string
+	if candidatos == nil {
+		candidatos = maps.Keys(votos[cargo])
+	}
+
+	votosForCandidato := make(map[string]int)
 	for _, candidato := range candidatos {
-		votosForCandidato = append(votosForCandidato, fmt.Sprint(votos[cargo][candidato]))
+		votosForCandidato[candidato] = votos[cargo][candidato]
 	}
 
 	apuracao, err := bu.Urna.ReadMotivoUtilizacaoSA()
@@ -91,100 +266,196 @@ func countVotos(bu urna.EntidadeBoletimUrna, cargo urna.CargoConstitucional, can
 		log.Println(err)
 	}
 
-	return append(
-		[]string{
-			bu.IdentificacaoSecao.Municipio().Uf,
-			bu.IdentificacaoSecao.Municipio().Nome,
-			bu.Urna.Tipo().String(),
-			bu.Urna.TipoDeArquivo().String(),
-			apuracao.Tipo().String(),
-			apuracao.Motivo(),
-			fmt.Sprint(bu.IdentificacaoSecao.MunicipioZona.Zona),
-			fmt.Sprint(bu.IdentificacaoSecao.Local),
-			fmt.Sprint(bu.IdentificacaoSecao.Secao),
-		}, votosForCandidato...)
+	return buCsvRecord{
+		Uf:              bu.Municipio().Uf,
+		Municipio:       bu.Municipio().Nome,
+		TipoUrna:        bu.Urna.Tipo().String(),
+		TipoArquivo:     bu.Urna.TipoDeArquivo().String(),
+		ApuracaoTipo:    apuracao.Tipo().String(),
+		ApuracaoMotivo:  apuracao.Motivo(),
+		Zona:            int(bu.IdentificacaoSecao.MunicipioZona.Zona),
+		Local:           int(bu.IdentificacaoSecao.Local),
+		Secao:           int(bu.IdentificacaoSecao.Secao),
+		Cargo:           cargo.String(),
+		Votos:           votosForCandidato,
+		candidatosOrder: candidatos,
+		localRecord:     localFor(bu.IdentificacaoSecao),
+	}
+}
+
+// One `bu count` record: total votes of a candidato (or Branco/Nulo) for a cargo.
+type buCountRecord struct {
+	Cargo     string `json:"cargo"`
+	Candidato string `json:"candidato"`
+	Votos     int    `json:"votos"`
 }
 
 func countBu(files []string) {
 	cargos := []urna.CargoConstitucional{urna.CargoConstitucionalFromString(cargo)}
 	votos := make(map[urna.CargoConstitucional]map[string]int)
 
-	for _, f := range files {
-		log.Printf("processing file %s", f)
-
-		if strings.HasSuffix(f, ".zip") {
-			urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
-				ebu, err := eeg.ReadBu()
-				if err != nil {
-					log.Fatal(err)
+	add := func(bu urna.EntidadeBoletimUrna) {
+		for cargo, candidato := range urna.CountVotosBu(bu, cargos) {
+			if votos[cargo] == nil {
+				votos[cargo] = candidato
+			} else {
+				for candidato, numVotos := range candidato {
+					votos[cargo][candidato] = votos[cargo][candidato] + numVotos
 				}
-				for cargo, candidato := range urna.CountVotosBu(ebu, cargos) {
-					if votos[cargo] == nil {
-						votos[cargo] = candidato
-					} else {
-						for candidato, numVotos := range candidato {
-							votos[cargo][candidato] = votos[cargo][candidato] + numVotos
-						}
-					}
-				}
-				return nil
-			})
+			}
 		}
+	}
 
-		if strings.HasSuffix(f, ".bu") {
-			entry := urna.BuEntry{Path: f}
-			bu, err := entry.ReadBu()
-			if err != nil {
-				log.Fatal(err)
+	forEachBu(files, add)
+
+	w := newRecordWriter("csv", []string{"Cargo", "Candidato", "Votos"})
+	for _, cargo := range cargos {
+		candidatos := maps.Keys(votos[cargo])
+		slices.Sort(candidatos)
+
+		for _, candidato := range candidatos {
+			r := buCountRecord{cargo.String(), candidato, votos[cargo][candidato]}
+			w.Write(r, []string{r.Cargo, r.Candidato, fmt.Sprint(r.Votos)})
+		}
+	}
+
+	w.Close()
+}
+
+func verifyBu(files []string) {
+	registry := urna.NewChaveRegistry()
+	if len(keys) > 0 {
+		var err error
+		registry, err = urna.LoadChaveRegistry(keys)
+		if err != nil {
+			inputError(err)
+			return
+		}
+	}
+
+	usage := urna.NewChaveUsage()
+	output, done := newVerificationOutput("")
+
+	verify := func(bu urna.EntidadeBoletimUrna) {
+		results := urna.ValidateVotosBu(bu)
+		if len(keys) > 0 || len(deriveKeys) > 0 {
+			results = append(results, registry.VerifyBu(bu))
+		}
+
+		output(results, localFor(bu.IdentificacaoSecao))
+
+		usage.Track(bu)
+	}
+
+	// A zip vouches for its own key, so keys are only trusted from other zips.
+	if len(deriveKeys) > 0 {
+		for _, f := range expandDirs(strings.Split(deriveKeys, ",")) {
+			if !strings.HasSuffix(f, ".zip") {
+				continue
 			}
 
-			for cargo, candidato := range urna.CountVotosBu(bu, cargos) {
-				if votos[cargo] == nil {
-					votos[cargo] = candidato
-				} else {
-					for candidato, numVotos := range candidato {
-						votos[cargo][candidato] = votos[cargo][candidato] + numVotos
-					}
-				}
+			if sameFile(f, files) {
+				inputError(fmt.Errorf("%s is verified and also trusted for keys", f))
+				return
 			}
+			registry.DeriveZip(f)
 		}
 	}
 
-	log.Println(votos)
+	forEachBu(files, verify)
+
+	output(usage.Results(), localRecord{})
+	done()
 }
 
-func verifyBu(files []string) {
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+// Whether `f` is one of `files`.
+func sameFile(f string, files []string) bool {
+	info, err := os.Stat(f)
+	if err != nil {
+		return false
+	}
 
+	for _, other := range expandDirs(files) {
+		o, err := os.Stat(other)
+		if err == nil && os.SameFile(info, o) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reports field-level differences between the BUs of two files (`*.bu` or section zips).
+func diffBu(files []string) {
+	var bus []urna.EntidadeBoletimUrna
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		bus = append(bus, bu)
+	})
+
+	if len(bus) != 2 {
+		inputError(fmt.Errorf("expected 2 BUs to compare, found %d", len(bus)))
+		return
+	}
+
+	w := newRecordWriter("table", []string{"Campo", files[0], files[1]})
+	diferencas := urna.DiffBu(bus[0], bus[1], ignoreAssinaturas)
+	for _, d := range diferencas {
+		w.Write(d, []string{d.Campo, d.A, d.B})
+	}
+	w.Close()
+
+	if len(diferencas) > 0 {
+		setExitCode(exitFailures)
+	}
+}
+
+// Calls `process` for every BU in `files` (`*.bu` files or section zips) that matches the filter.
+func forEachBu(files []string, process func(urna.EntidadeBoletimUrna)) {
+	forEachSectionFile(files, func(f string) {
 		if strings.HasSuffix(f, ".zip") {
-			urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
-				ebu, err := eeg.ReadBu()
+			err := urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
+				if stopped() {
+					return nil
+				}
+
+				bu, err := eeg.ReadBu()
+				if errors.Is(err, urna.ErrMesaJustificativa) {
+					log.Println("skipping", f, err)
+					return nil
+				}
 				if err != nil {
-					log.Fatal(err)
+					inputError(err)
+					return err
 				}
 
-				results := urna.ValidateVotosBu(ebu)
-				for _, r := range results {
-					log.Println(r.Msg())
+				if filtro.MatchBu(bu) {
+					process(bu)
 				}
 
 				return nil
 			})
+			if err != nil {
+				inputError(err)
+			}
 		}
+
 		if strings.HasSuffix(f, ".bu") {
 			entry := urna.BuEntry{Path: f}
 			bu, err := entry.ReadBu()
+			if errors.Is(err, urna.ErrMesaJustificativa) {
+				log.Println("skipping", f, err)
+				return
+			}
 			if err != nil {
-				log.Fatal(err)
+				inputError(err)
+				return
 			}
 
-			results := urna.ValidateVotosBu(bu)
-			for _, r := range results {
-				log.Println(r.Msg())
+			if filtro.MatchBu(bu) {
+				process(bu)
 			}
 		}
-	}
+	})
 }
 
 func countBuFlags() []string {
@@ -193,13 +464,13 @@ func countBuFlags() []string {
 
 	err := countFlags.Parse(os.Args[3:])
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitInputError)
 	}
 
 	if len(cargo) == 0 {
 		fmt.Println("usage: urna bu count -cargo <cargo> <file_1> ... <file_n>")
 		countFlags.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitInputError)
 	}
 
 	return countFlags.Args()
@@ -207,38 +478,91 @@ func countBuFlags() []string {
 
 func csvBuFlags() []string {
 	csvFlags := flag.NewFlagSet("csv", flag.ContinueOnError)
-	csvFlags.StringVar(&cargo, "cargo", "", "e.g. Presidente")
-	csvFlags.StringVar(&candidatos, "candidatos", "", "Comma-separated list; e.g. 'Branco,Nulo,99'")
+	csvFlags.StringVar(&cargo, "cargo", "", "e.g. Presidente; with -long, a comma-separated list (default all cargos)")
+	csvFlags.StringVar(&candidatos, "candidatos", "", "Comma-separated list; e.g. 'Branco,Nulo,99' (default all found)")
+	csvFlags.BoolVar(&long, "long", false, "One row per section, cargo and votável")
 	err := csvFlags.Parse(os.Args[3:])
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitInputError)
 	}
 
-	if len(cargo) == 0 || len(candidatos) == 0 {
-		fmt.Println("usage: urna bu csv -cargo <cargo> -candidatos <candidatos> <file_1> ... <file_n>")
+	if !long && len(cargo) == 0 {
+		fmt.Println("usage: urna bu csv (-cargo <cargo> [-candidatos <candidatos>] | -long [-cargo <cargos>]) <file_1> ... <file_n>")
 		csvFlags.PrintDefaults()
-		os.Exit(1)
+		os.Exit(exitInputError)
 	}
 
 	return csvFlags.Args()
 }
 
 func verifyBuFlags() []string {
-	if len(os.Args) > 3 {
-		return os.Args[3:]
+	verifyFlags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	verifyFlags.StringVar(&keys, "keys", "", "CSV of expected BU keys; rows '<municipio>,<zona>,<secao>,<hex>' or '<carga>,<hex>'")
+	verifyFlags.StringVar(&deriveKeys, "derive-keys", "", "Comma-separated trusted zips or directories whose BU keys are registered if their .vscmr signature verifies; must not include the files verified")
+
+	err := verifyFlags.Parse(os.Args[3:])
+	if err != nil {
+		os.Exit(exitInputError)
 	}
 
-	fmt.Println("usage: urna bu verify <file_1> ... <file_n>")
-	os.Exit(1)
+	if len(verifyFlags.Args()) == 0 {
+		fmt.Println("usage: urna bu verify [-keys <file>] [-derive-keys <zips>] <file_1> ... <file_n>")
+		verifyFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
 
-	return []string{}
+	return verifyFlags.Args()
+}
+
+func diffBuFlags() []string {
+	diffFlags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	diffFlags.BoolVar(&ignoreAssinaturas, "ignore-assinaturas", false, "Ignore signature bytes (votável tuple signatures and BU key)")
+
+	err := diffFlags.Parse(os.Args[3:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(diffFlags.Args()) != 2 {
+		fmt.Println("usage: urna bu diff [-ignore-assinaturas] <file_a> <file_b>")
+		diffFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return diffFlags.Args()
+}
+
+func locaisBuFlags() []string {
+	locaisFlags := flag.NewFlagSet("locais", flag.ContinueOnError)
+	locaisFlags.StringVar(&cargo, "cargo", "", "Comma-separated list (default all cargos)")
+
+	err := locaisFlags.Parse(os.Args[3:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(locaisFlags.Args()) == 0 {
+		fmt.Println("usage: urna bu locais [-cargo <cargos>] <file_1> ... <file_n>")
+		locaisFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return locaisFlags.Args()
 }
 
 func splitCandidatosIntoSlice() []string {
-	candidatos := strings.Split(candidatos, ",")
-	for i := range candidatos {
-		candidatos[i] = strings.Trim(candidatos[i], " ")
+	if len(candidatos) == 0 {
+		return nil
 	}
 
-	return candidatos
+	return splitIntoSlice(candidatos)
+}
+
+func splitIntoSlice(list string) []string {
+	items := strings.Split(list, ",")
+	for i := range items {
+		items[i] = strings.Trim(items[i], " ")
+	}
+
+	return items
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+// Lists the urna and flash cards of every section, flagging urnas and flash
+// de votação cards also found in another section.
+func Carga() {
+	files := os.Args[2:]
+	if len(files) == 0 {
+		fmt.Println("usage: urna carga <zip|bu> ...")
+		os.Exit(exitInputError)
+	}
+
+	var infos []urna.CargaInfo
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		infos = append(infos, urna.CargaFromBu(bu))
+	})
+
+	urna.FlagCargaReuse(infos)
+
+	w := newRecordWriter("csv",
+		[]string{
+			"Municipio",
+			"Zona",
+			"Secao",
+			"Tipo urna",
+			"Numero interno urna",
+			"Numero serie FC",
+			"Numero serie FV",
+			"Data hora carga (UTC)",
+			"Codigo carga",
+			"Secoes flash carga",
+			"Urna reutilizada",
+			"Flash votacao reutilizada",
+			"Erro"})
+
+	for _, i := range infos {
+		var carga string
+		if !i.DataHoraCarga.IsZero() {
+			carga = i.DataHoraCarga.Format("2006-01-02 15:04:05")
+		}
+
+		w.Write(i, []string{
+			i.Municipio,
+			i.Zona,
+			i.Secao,
+			i.TipoUrna,
+			fmt.Sprint(i.NumeroInternoUrna),
+			fmt.Sprint(i.NumeroSerieFC),
+			fmt.Sprint(i.NumeroSerieFV),
+			carga,
+			i.CodigoCarga,
+			fmt.Sprint(i.SecoesFlashCarga),
+			fmt.Sprint(i.UrnaReutilizada),
+			fmt.Sprint(i.FlashVotacaoReutilizada),
+			i.Erro})
+	}
+
+	w.Close()
+}
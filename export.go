@@ -0,0 +1,556 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	urna "github.com/mpbertram/urna/ue"
+	_ "modernc.org/sqlite"
+)
+
+func Export() {
+	var function string
+	if len(os.Args) > 2 {
+		function = os.Args[2]
+	}
+
+	switch function {
+	case "sqlite":
+		exportSqlite(exportFlags())
+	case "parquet":
+		exportParquet(exportFlags())
+	default:
+		fmt.Println("usage: urna export sqlite <db> <file_1> ... <file_n>")
+		fmt.Println("       urna export parquet <dir> <file_1> ... <file_n>")
+		fmt.Printf("provided function '%s' is none of (sqlite, parquet)\n", function)
+		setExitCode(exitInputError)
+	}
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS arquivo (
+	id           INTEGER PRIMARY KEY,
+	path         TEXT NOT NULL,
+	sha256       TEXT NOT NULL UNIQUE,
+	carregado_em TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS secao (
+	id        INTEGER PRIMARY KEY,
+	municipio INTEGER NOT NULL,
+	nome      TEXT,
+	uf        TEXT,
+	zona      INTEGER NOT NULL,
+	local     INTEGER,
+	secao     INTEGER NOT NULL,
+	UNIQUE (municipio, zona, secao)
+);
+
+CREATE TABLE IF NOT EXISTS urna (
+	id                  INTEGER PRIMARY KEY,
+	numero_interno_urna INTEGER NOT NULL,
+	codigo_carga        TEXT NOT NULL,
+	numero_serie_fc     TEXT,
+	data_hora_carga     TEXT,
+	tipo_urna           TEXT,
+	versao_votacao      TEXT,
+	UNIQUE (numero_interno_urna, codigo_carga)
+);
+
+CREATE TABLE IF NOT EXISTS bu (
+	id                            INTEGER PRIMARY KEY,
+	arquivo_id                    INTEGER NOT NULL REFERENCES arquivo (id),
+	secao_id                      INTEGER NOT NULL REFERENCES secao (id),
+	urna_id                       INTEGER NOT NULL REFERENCES urna (id),
+	fase                          TEXT,
+	tipo_arquivo                  TEXT,
+	numero_serie_fv               TEXT,
+	apuracao_tipo                 TEXT,
+	apuracao_motivo               TEXT,
+	data_geracao                  TEXT,
+	data_hora_emissao             TEXT,
+	data_hora_abertura            TEXT,
+	data_hora_encerramento        TEXT,
+	qtd_eleitores_lib_codigo      INTEGER,
+	qtd_eleitores_comp_biometrico INTEGER,
+	chave                         TEXT
+);
+
+CREATE TABLE IF NOT EXISTS voto_bu (
+	bu_id      INTEGER NOT NULL REFERENCES bu (id),
+	id_eleicao INTEGER NOT NULL,
+	cargo      TEXT NOT NULL,
+	tipo_voto  TEXT NOT NULL,
+	partido    INTEGER,
+	votavel    INTEGER,
+	quantidade INTEGER NOT NULL,
+	assinatura TEXT
+);
+
+CREATE TABLE IF NOT EXISTS rdv (
+	id              INTEGER PRIMARY KEY,
+	arquivo_id      INTEGER NOT NULL REFERENCES arquivo (id),
+	secao_id        INTEGER NOT NULL REFERENCES secao (id),
+	urna_id         INTEGER NOT NULL REFERENCES urna (id),
+	fase            TEXT,
+	tipo_arquivo    TEXT,
+	numero_serie_fv TEXT,
+	data_geracao    TEXT
+);
+
+CREATE TABLE IF NOT EXISTS voto_rdv (
+	rdv_id     INTEGER NOT NULL REFERENCES rdv (id),
+	id_eleicao INTEGER NOT NULL,
+	cargo      TEXT NOT NULL,
+	tipo_voto  TEXT NOT NULL,
+	digitacao  TEXT
+);
+
+CREATE TABLE IF NOT EXISTS verificacao (
+	arquivo_id INTEGER NOT NULL REFERENCES arquivo (id),
+	filename   TEXT,
+	municipio  TEXT,
+	zona       TEXT,
+	secao      TEXT,
+	tipo       TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	erro       TEXT,
+	motivo     TEXT
+);
+
+CREATE TABLE IF NOT EXISTS certificado (
+	arquivo_id        INTEGER NOT NULL REFERENCES arquivo (id),
+	filename          TEXT,
+	municipio         TEXT,
+	zona              TEXT,
+	secao             TEXT,
+	modelo_urna       TEXT,
+	assinatura        TEXT,
+	nome_usuario      TEXT,
+	serial_usuario    INTEGER,
+	conjunto_chave    TEXT,
+	subject           TEXT,
+	serial            TEXT,
+	issuer            TEXT,
+	not_before        TEXT,
+	not_after         TEXT,
+	fingerprint_chave TEXT,
+	fingerprint_cert  TEXT,
+	erro              TEXT
+);
+
+CREATE INDEX IF NOT EXISTS bu_secao ON bu (secao_id);
+CREATE INDEX IF NOT EXISTS bu_arquivo ON bu (arquivo_id);
+CREATE INDEX IF NOT EXISTS voto_bu_bu ON voto_bu (bu_id);
+CREATE INDEX IF NOT EXISTS voto_bu_cargo ON voto_bu (cargo, votavel);
+CREATE INDEX IF NOT EXISTS rdv_secao ON rdv (secao_id);
+CREATE INDEX IF NOT EXISTS voto_rdv_rdv ON voto_rdv (rdv_id);
+CREATE INDEX IF NOT EXISTS verificacao_arquivo ON verificacao (arquivo_id);
+CREATE INDEX IF NOT EXISTS verificacao_status ON verificacao (status, tipo);
+CREATE INDEX IF NOT EXISTS certificado_chave ON certificado (fingerprint_chave);
+`
+
+// Loads sections, urnas, votes, verification results and certificates of
+// `files` into the SQLite database `files[0]`; files already loaded (by
+// SHA-256) are skipped.
+func exportSqlite(files []string) {
+	db, err := sql.Open("sqlite", files[0])
+	if err != nil {
+		inputError(err)
+		return
+	}
+	defer db.Close()
+
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		inputError(err)
+		return
+	}
+
+	forEachSectionFile(files[1:], func(f string) {
+		err := loadFile(db, f)
+		if errors.Is(err, errAlreadyLoaded) {
+			log.Printf("skipping %s (already loaded)", f)
+			return
+		}
+		if err != nil {
+			inputError(fmt.Errorf("%s: %w", f, err))
+		}
+	})
+}
+
+var errAlreadyLoaded = errors.New("already loaded")
+
+// Loads one input file in a single transaction.
+func loadFile(db *sql.DB, f string) error {
+	hash, err := sha256File(f)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	err = tx.QueryRow("SELECT count(*) FROM arquivo WHERE sha256 = ?", hash).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errAlreadyLoaded
+	}
+
+	res, err := tx.Exec("INSERT INTO arquivo (path, sha256, carregado_em) VALUES (?, ?, ?)",
+		f, hash, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	arquivo, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	// Files with nothing passing the filter are not recorded, so that they
+	// are loaded by a later export with another filter.
+	var matched bool
+
+	l := loader{tx, arquivo}
+	switch filepath.Ext(f) {
+	case ".zip":
+		matched, err = l.loadZip(f)
+	case ".bu":
+		var bu urna.EntidadeBoletimUrna
+		bu, err = urna.BuEntry{Path: f}.ReadBu()
+		if err == nil && filtro.MatchBu(bu) {
+			matched = true
+			err = l.loadBu(bu)
+		}
+	case ".rdv":
+		var rdv urna.EntidadeResultadoRDV
+		rdv, err = urna.ReadRdv(f)
+		if err == nil && filtro.MatchRdv(rdv) {
+			matched = true
+			err = l.loadRdv(rdv)
+		}
+	case ".vscmr":
+		matched, err = filtro.MatchVscmr(f)
+		if err != nil || !matched {
+			break
+		}
+		err = l.loadVerificacoes(append(urna.VerifyAssinaturaVscmr(f), urna.VerifyCertsVscmr(f)...))
+		if err == nil {
+			err = l.loadCertificados(urna.InventoryVscmr(f))
+		}
+	default:
+		return fmt.Errorf("unsupported file type %s", filepath.Ext(f))
+	}
+	if err != nil || !matched {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type loader struct {
+	tx      *sql.Tx
+	arquivo int64
+}
+
+// Loads the BUs and RDVs of a zip passing the filter and, if there was any,
+// its verifications and certificates.
+func (l loader) loadZip(f string) (bool, error) {
+	var loadErr error
+	keep := func(err error) {
+		if err != nil && loadErr == nil {
+			loadErr = err
+		}
+	}
+
+	matched := filtro.IsEmpty()
+
+	keep(urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
+		if stopped() {
+			return nil
+		}
+
+		bu, err := eeg.ReadBu()
+		if err == nil && filtro.MatchBu(bu) {
+			matched = true
+			err = l.loadBu(bu)
+		}
+		keep(err)
+		return err
+	}))
+
+	keep(urna.ProcessZip(f, func(rdv urna.EntidadeResultadoRDV) error {
+		if stopped() {
+			return nil
+		}
+
+		var err error
+		if filtro.MatchRdv(rdv) {
+			matched = true
+			err = l.loadRdv(rdv)
+		}
+		keep(err)
+		return err
+	}))
+
+	if loadErr != nil || !matched {
+		return matched, loadErr
+	}
+
+	err := l.loadVerificacoes(append(urna.VerifyAssinaturaZip(f), urna.VerifyCertsZip(f)...))
+	if err != nil {
+		return matched, err
+	}
+
+	return matched, l.loadCertificados(urna.InventoryZip(f))
+}
+
+func (l loader) loadBu(bu urna.EntidadeBoletimUrna) error {
+	secao, err := l.secao(bu.IdentificacaoSecao, bu.Municipio())
+	if err != nil {
+		return err
+	}
+
+	u, err := l.urna(bu.Urna)
+	if err != nil {
+		return err
+	}
+
+	var abertura, encerramento string
+	dados, err := bu.ReadDadosSecaoSA()
+	if d, ok := dados.(urna.DadosSecao); err == nil && ok {
+		abertura, encerramento = string(d.DataHoraAbertura), string(d.DataHoraEncerramento)
+	}
+
+	apuracaoTipo, apuracaoMotivo := "", ""
+	apuracao, err := bu.Urna.ReadMotivoUtilizacaoSA()
+	if err == nil {
+		apuracaoTipo, apuracaoMotivo = apuracao.Tipo().String(), apuracao.Motivo()
+	}
+
+	fase, _ := urna.FaseFromEnumerated(bu.Fase)
+
+	res, err := l.tx.Exec(`INSERT INTO bu (
+		arquivo_id, secao_id, urna_id, fase, tipo_arquivo, numero_serie_fv,
+		apuracao_tipo, apuracao_motivo, data_geracao, data_hora_emissao,
+		data_hora_abertura, data_hora_encerramento,
+		qtd_eleitores_lib_codigo, qtd_eleitores_comp_biometrico, chave
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		l.arquivo, secao, u, fase.String(), bu.Urna.TipoDeArquivo().String(), hex.EncodeToString(bu.Urna.NumeroSerieFV),
+		apuracaoTipo, apuracaoMotivo, string(bu.Cabecalho.DataGeracao), string(bu.DataHoraEmissao),
+		abertura, encerramento,
+		bu.QtdEleitoresLibCodigo, bu.QtdEleitoresCompBiometrico, hex.EncodeToString(bu.ChaveAssinaturaVotosVotavel))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	err = l.loadVerificacoes(urna.ValidateVotosBu(bu))
+	if err != nil {
+		return err
+	}
+
+	stmt, err := l.tx.Prepare(`INSERT INTO voto_bu (
+		bu_id, id_eleicao, cargo, tipo_voto, partido, votavel, quantidade, assinatura
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, v := range urna.ListVotosBu(bu) {
+		_, err := stmt.Exec(id, int(v.IdEleicao), v.Cargo, v.TipoVoto.String(),
+			nullIfZero(int(v.Partido)), nullIfZero(int(v.Codigo)), v.Quantidade, hex.EncodeToString(v.Assinatura))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l loader) loadRdv(rdv urna.EntidadeResultadoRDV) error {
+	secao, err := l.secao(rdv.Rdv.Identificacao, rdv.Municipio())
+	if err != nil {
+		return err
+	}
+
+	u, err := l.urna(rdv.Urna)
+	if err != nil {
+		return err
+	}
+
+	fase, _ := urna.FaseFromEnumerated(rdv.Rdv.Fase)
+
+	res, err := l.tx.Exec(`INSERT INTO rdv (
+		arquivo_id, secao_id, urna_id, fase, tipo_arquivo, numero_serie_fv, data_geracao
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		l.arquivo, secao, u, fase.String(), rdv.Urna.TipoDeArquivo().String(),
+		hex.EncodeToString(rdv.Urna.NumeroSerieFV), string(rdv.Cabecalho.DataGeracao))
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	votos, err := urna.ListVotosRdv(rdv)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := l.tx.Prepare(`INSERT INTO voto_rdv (
+		rdv_id, id_eleicao, cargo, tipo_voto, digitacao
+	) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, v := range votos {
+		_, err := stmt.Exec(id, v.IdEleicao, v.Cargo, v.TipoVoto.String(), string(v.Digitacao))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the id of the section, inserting it if needed.
+func (l loader) secao(id urna.IdentificacaoSecaoEleitoral, m urna.Municipio) (int64, error) {
+	_, err := l.tx.Exec(`INSERT OR IGNORE INTO secao (municipio, nome, uf, zona, local, secao) VALUES (?, ?, ?, ?, ?, ?)`,
+		int(id.MunicipioZona.Municipio), m.Nome, m.Uf, int(id.MunicipioZona.Zona), int(id.Local), int(id.Secao))
+	if err != nil {
+		return 0, err
+	}
+
+	var secao int64
+	err = l.tx.QueryRow(`SELECT id FROM secao WHERE municipio = ? AND zona = ? AND secao = ?`,
+		int(id.MunicipioZona.Municipio), int(id.MunicipioZona.Zona), int(id.Secao)).Scan(&secao)
+
+	return secao, err
+}
+
+// Returns the id of the urna and carga, inserting it if needed.
+func (l loader) urna(u urna.Urna) (int64, error) {
+	c := u.CorrespondenciaResultado.Carga
+	_, err := l.tx.Exec(`INSERT OR IGNORE INTO urna (
+		numero_interno_urna, codigo_carga, numero_serie_fc, data_hora_carga, tipo_urna, versao_votacao
+	) VALUES (?, ?, ?, ?, ?, ?)`,
+		int(c.NumeroInternoUrna), c.CodigoCarga, hex.EncodeToString(c.NumeroSerieFC), string(c.DataHoraCarga),
+		u.Tipo().String(), u.VersaoVotacao)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = l.tx.QueryRow(`SELECT id FROM urna WHERE numero_interno_urna = ? AND codigo_carga = ?`,
+		int(c.NumeroInternoUrna), c.CodigoCarga).Scan(&id)
+
+	return id, err
+}
+
+func (l loader) loadVerificacoes(results []urna.VerificationResult) error {
+	trackResults(results)
+
+	stmt, err := l.tx.Prepare(`INSERT INTO verificacao (
+		arquivo_id, filename, municipio, zona, secao, tipo, status, erro, motivo
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range results {
+		var e string
+		if r.Err != nil {
+			e = r.Err.Error()
+		}
+
+		_, err := stmt.Exec(l.arquivo, r.Filename, r.Municipio, r.Zona, r.Secao,
+			r.Type.String(), r.Ok.String(), e, r.Reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l loader) loadCertificados(infos []urna.CertificadoInfo) error {
+	stmt, err := l.tx.Prepare(`INSERT INTO certificado (
+		arquivo_id, filename, municipio, zona, secao, modelo_urna, assinatura,
+		nome_usuario, serial_usuario, conjunto_chave, subject, serial, issuer,
+		not_before, not_after, fingerprint_chave, fingerprint_cert, erro
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, i := range infos {
+		_, err := stmt.Exec(l.arquivo, i.Filename, i.Municipio, i.Zona, i.Secao, i.ModeloUrna, i.Assinatura,
+			i.NomeUsuario, i.SerialUsuario, i.ConjuntoChave, i.Subject, i.Serial, i.Issuer,
+			i.NotBefore, i.NotAfter, i.FingerprintChave, i.FingerprintCert, i.ErroCertificado)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func nullIfZero(n int) any {
+	if n == 0 {
+		return nil
+	}
+
+	return n
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func exportFlags() []string {
+	if len(os.Args) > 4 {
+		return os.Args[3:]
+	}
+
+	fmt.Println("usage: urna export sqlite <db> <file_1> ... <file_n>")
+	fmt.Println("       urna export parquet <dir> <file_1> ... <file_n>")
+	os.Exit(exitInputError)
+
+	return []string{}
+}
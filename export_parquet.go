@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/certificate-transparency-go/asn1"
+	urna "github.com/mpbertram/urna/ue"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Section metadata, one row per BU or RDV.
+type secaoParquet struct {
+	Municipio                  int32  `parquet:"municipio"`
+	NomeMunicipio              string `parquet:"nome_municipio,dict"`
+	Zona                       int32  `parquet:"zona"`
+	Local                      int32  `parquet:"local"`
+	Secao                      int32  `parquet:"secao"`
+	Origem                     string `parquet:"origem,enum"`
+	Fase                       string `parquet:"fase,enum"`
+	TipoUrna                   string `parquet:"tipo_urna,enum"`
+	TipoArquivo                string `parquet:"tipo_arquivo,enum"`
+	NumeroInternoUrna          int32  `parquet:"numero_interno_urna"`
+	CodigoCarga                string `parquet:"codigo_carga"`
+	NumeroSerieFC              string `parquet:"numero_serie_fc"`
+	NumeroSerieFV              string `parquet:"numero_serie_fv,optional"`
+	VersaoVotacao              string `parquet:"versao_votacao,dict"`
+	DataHoraCarga              int64  `parquet:"data_hora_carga,timestamp(millisecond),optional"`
+	DataGeracao                int64  `parquet:"data_geracao,timestamp(millisecond),optional"`
+	DataHoraEmissao            int64  `parquet:"data_hora_emissao,timestamp(millisecond),optional"`
+	DataHoraAbertura           int64  `parquet:"data_hora_abertura,timestamp(millisecond),optional"`
+	DataHoraEncerramento       int64  `parquet:"data_hora_encerramento,timestamp(millisecond),optional"`
+	ApuracaoTipo               string `parquet:"apuracao_tipo,enum,optional"`
+	ApuracaoMotivo             string `parquet:"apuracao_motivo,optional"`
+	QtdEleitoresLibCodigo      int32  `parquet:"qtd_eleitores_lib_codigo,optional"`
+	QtdEleitoresCompBiometrico int32  `parquet:"qtd_eleitores_comp_biometrico,optional"`
+	Arquivo                    string `parquet:"arquivo,dict"`
+}
+
+// One BU tuple; partido and votavel are null for Branco and Nulo.
+type votoBuParquet struct {
+	Municipio  int32  `parquet:"municipio"`
+	Zona       int32  `parquet:"zona"`
+	Secao      int32  `parquet:"secao"`
+	IdEleicao  int32  `parquet:"id_eleicao"`
+	Cargo      string `parquet:"cargo,dict"`
+	TipoVoto   string `parquet:"tipo_voto,enum"`
+	Partido    int32  `parquet:"partido,optional"`
+	Votavel    int32  `parquet:"votavel,optional"`
+	Quantidade int32  `parquet:"quantidade"`
+}
+
+// One RDV vote; digitacao is null for Branco and Nulo.
+type votoRdvParquet struct {
+	Municipio int32  `parquet:"municipio"`
+	Zona      int32  `parquet:"zona"`
+	Secao     int32  `parquet:"secao"`
+	IdEleicao int32  `parquet:"id_eleicao"`
+	Cargo     string `parquet:"cargo,dict"`
+	TipoVoto  string `parquet:"tipo_voto,enum"`
+	Digitacao string `parquet:"digitacao,optional"`
+}
+
+// Writes `<dir>/<table>/uf=<UF>/<table>.parquet` for the tables secao,
+// voto_bu and voto_rdv (hive partitioning); existing files are replaced.
+type parquetExporter struct {
+	dir      string
+	secoes   map[string]*parquet.GenericWriter[secaoParquet]
+	votosBu  map[string]*parquet.GenericWriter[votoBuParquet]
+	votosRdv map[string]*parquet.GenericWriter[votoRdvParquet]
+	closers  []func() error
+}
+
+// Exports sections, BU tuples and RDV votes of `files` as Parquet under the
+// directory `files[0]`, partitioned by UF.
+func exportParquet(files []string) {
+	e := parquetExporter{
+		dir:      files[0],
+		secoes:   map[string]*parquet.GenericWriter[secaoParquet]{},
+		votosBu:  map[string]*parquet.GenericWriter[votoBuParquet]{},
+		votosRdv: map[string]*parquet.GenericWriter[votoRdvParquet]{},
+	}
+
+	forEachSectionFile(files[1:], func(f string) {
+		err := e.exportFile(f)
+		if err != nil {
+			inputError(fmt.Errorf("%s: %w", f, err))
+		}
+	})
+
+	err := e.close()
+	if err != nil {
+		inputError(err)
+	}
+}
+
+func (e *parquetExporter) exportFile(f string) error {
+	switch filepath.Ext(f) {
+	case ".zip":
+		err := urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
+			if stopped() {
+				return nil
+			}
+
+			bu, err := eeg.ReadBu()
+			if err != nil || !filtro.MatchBu(bu) {
+				return err
+			}
+			return e.exportBu(f, bu)
+		})
+		if err != nil {
+			return err
+		}
+
+		return urna.ProcessZip(f, func(rdv urna.EntidadeResultadoRDV) error {
+			if stopped() || !filtro.MatchRdv(rdv) {
+				return nil
+			}
+			return e.exportRdv(f, rdv)
+		})
+	case ".bu":
+		bu, err := urna.BuEntry{Path: f}.ReadBu()
+		if err != nil || !filtro.MatchBu(bu) {
+			return err
+		}
+		return e.exportBu(f, bu)
+	case ".rdv":
+		rdv, err := urna.ReadRdv(f)
+		if err != nil || !filtro.MatchRdv(rdv) {
+			return err
+		}
+		return e.exportRdv(f, rdv)
+	}
+
+	return fmt.Errorf("unsupported file type %s", filepath.Ext(f))
+}
+
+func (e *parquetExporter) exportBu(f string, bu urna.EntidadeBoletimUrna) error {
+	id := bu.IdentificacaoSecao
+	s := newSecaoParquet(f, "bu", bu.Fase, id, bu.Municipio(), bu.Urna, bu.Cabecalho)
+	s.DataHoraEmissao = parseDataHora(bu.DataHoraEmissao, bu.Municipio())
+	s.QtdEleitoresLibCodigo = int32(bu.QtdEleitoresLibCodigo)
+	s.QtdEleitoresCompBiometrico = int32(bu.QtdEleitoresCompBiometrico)
+
+	dados, err := bu.ReadDadosSecaoSA()
+	if d, ok := dados.(urna.DadosSecao); err == nil && ok {
+		s.DataHoraAbertura = parseDataHora(d.DataHoraAbertura, bu.Municipio())
+		s.DataHoraEncerramento = parseDataHora(d.DataHoraEncerramento, bu.Municipio())
+	}
+
+	uf := partitionUf(bu.Municipio())
+	w, err := parquetWriter(e, e.secoes, "secao", uf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]secaoParquet{s})
+	if err != nil {
+		return err
+	}
+
+	var votos []votoBuParquet
+	for _, v := range urna.ListVotosBu(bu) {
+		votos = append(votos, votoBuParquet{
+			Municipio:  int32(id.MunicipioZona.Municipio),
+			Zona:       int32(id.MunicipioZona.Zona),
+			Secao:      int32(id.Secao),
+			IdEleicao:  int32(v.IdEleicao),
+			Cargo:      v.Cargo,
+			TipoVoto:   v.TipoVoto.String(),
+			Partido:    int32(v.Partido),
+			Votavel:    int32(v.Codigo),
+			Quantidade: int32(v.Quantidade),
+		})
+	}
+
+	wv, err := parquetWriter(e, e.votosBu, "voto_bu", uf)
+	if err != nil {
+		return err
+	}
+	_, err = wv.Write(votos)
+
+	return err
+}
+
+func (e *parquetExporter) exportRdv(f string, rdv urna.EntidadeResultadoRDV) error {
+	id := rdv.Rdv.Identificacao
+	s := newSecaoParquet(f, "rdv", rdv.Rdv.Fase, id, rdv.Municipio(), rdv.Urna, rdv.Cabecalho)
+
+	uf := partitionUf(rdv.Municipio())
+	w, err := parquetWriter(e, e.secoes, "secao", uf)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write([]secaoParquet{s})
+	if err != nil {
+		return err
+	}
+
+	votosRdv, err := urna.ListVotosRdv(rdv)
+	if err != nil {
+		return err
+	}
+
+	var votos []votoRdvParquet
+	for _, v := range votosRdv {
+		votos = append(votos, votoRdvParquet{
+			Municipio: int32(id.MunicipioZona.Municipio),
+			Zona:      int32(id.MunicipioZona.Zona),
+			Secao:     int32(id.Secao),
+			IdEleicao: int32(v.IdEleicao),
+			Cargo:     v.Cargo,
+			TipoVoto:  v.TipoVoto.String(),
+			Digitacao: string(v.Digitacao),
+		})
+	}
+
+	wv, err := parquetWriter(e, e.votosRdv, "voto_rdv", uf)
+	if err != nil {
+		return err
+	}
+	_, err = wv.Write(votos)
+
+	return err
+}
+
+func newSecaoParquet(f, origem string, fase asn1.Enumerated, id urna.IdentificacaoSecaoEleitoral, m urna.Municipio, u urna.Urna, c urna.CabecalhoEntidade) secaoParquet {
+	fs, _ := urna.FaseFromEnumerated(fase)
+	carga := u.CorrespondenciaResultado.Carga
+
+	s := secaoParquet{
+		Municipio:         int32(id.MunicipioZona.Municipio),
+		NomeMunicipio:     m.Nome,
+		Zona:              int32(id.MunicipioZona.Zona),
+		Local:             int32(id.Local),
+		Secao:             int32(id.Secao),
+		Origem:            origem,
+		Fase:              fs.String(),
+		TipoUrna:          u.Tipo().String(),
+		TipoArquivo:       u.TipoDeArquivo().String(),
+		NumeroInternoUrna: int32(carga.NumeroInternoUrna),
+		CodigoCarga:       carga.CodigoCarga,
+		NumeroSerieFC:     hex.EncodeToString(carga.NumeroSerieFC),
+		NumeroSerieFV:     hex.EncodeToString(u.NumeroSerieFV),
+		VersaoVotacao:     u.VersaoVotacao,
+		DataHoraCarga:     parseDataHora(carga.DataHoraCarga, m),
+		DataGeracao:       parseDataHora(c.DataGeracao, m),
+		Arquivo:           f,
+	}
+
+	apuracao, err := u.ReadMotivoUtilizacaoSA()
+	if err == nil {
+		s.ApuracaoTipo, s.ApuracaoMotivo = apuracao.Tipo().String(), apuracao.Motivo()
+	}
+
+	return s
+}
+
+// Returns the writer of `table` for `uf`, creating its file if needed.
+func parquetWriter[T any](e *parquetExporter, writers map[string]*parquet.GenericWriter[T], table, uf string) (*parquet.GenericWriter[T], error) {
+	w, ok := writers[uf]
+	if ok {
+		return w, nil
+	}
+
+	dir := filepath.Join(e.dir, table, "uf="+uf)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(filepath.Join(dir, table+".parquet"))
+	if err != nil {
+		return nil, err
+	}
+
+	w = parquet.NewGenericWriter[T](f, parquet.Compression(&parquet.Zstd))
+	writers[uf] = w
+	e.closers = append(e.closers, w.Close, f.Close)
+
+	return w, nil
+}
+
+func (e *parquetExporter) close() error {
+	var errs []error
+	for _, c := range e.closers {
+		errs = append(errs, c())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sections of unknown municípios go to `uf=desconhecida`.
+func partitionUf(m urna.Municipio) string {
+	if len(m.Uf) != 2 {
+		return "desconhecida"
+	}
+
+	return m.Uf
+}
+
+// Milliseconds since the epoch of a local time of the urnas of `m`;
+// unparseable dates are zero, written as null.
+func parseDataHora(d urna.DataHoraJE, m urna.Municipio) int64 {
+	t, err := d.TimeIn(m.Location())
+	if err != nil {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+var filtro urna.Filtro
+
+func filterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&filtro.Uf, "uf", "", "Only sections of this UF")
+	flags.StringVar(&filtro.Municipio, "municipio", "", "Only sections of this município (name or code)")
+	flags.IntVar(&filtro.Zona, "zona", 0, "Only sections of this zona")
+	flags.IntVar(&filtro.Secao, "secao", 0, "Only this seção number")
+	flags.StringVar(&filtro.Fase, "fase", "", "Only this fase: oficial|simulado|treinamento")
+	flags.StringVar(&filtro.TipoUrna, "tipo-urna", "", "Only this tipo de urna, e.g. secao|contingencia")
+	flags.StringVar(&filtro.TipoArquivo, "tipo-arquivo", "", "Only this tipo de arquivo, e.g. votacaoue|votacaored")
+	flags.StringVar(&filtro.Apuracao, "apuracao", "", "Only this tipo de apuração, e.g. normal|totalmentemanual")
+}
+
+// Applies the filter to a file before it is processed: by filename, then
+// for zips and `*.vscmr` by the BU (or RDV) of the section.
+func matchFilter(f string) bool {
+	if filtro.IsEmpty() {
+		return true
+	}
+
+	if !filtro.MatchFilename(f) {
+		return false
+	}
+
+	var ok bool
+	var err error
+	switch {
+	case strings.HasSuffix(f, ".zip"):
+		ok, err = filtro.MatchZip(f)
+	case strings.HasSuffix(f, ".vscmr"):
+		ok, err = filtro.MatchVscmr(f)
+	default:
+		// `*.bu` and `*.rdv` are matched by the commands decoding them.
+		return true
+	}
+
+	if err != nil {
+		inputError(err)
+		return false
+	}
+
+	return ok
+}
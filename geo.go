@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var geoPor string
+var geometria string
+var propriedade string
+
+// Votes of one cargo in an area, by votável number (Branco and Nulo by name).
+type votosCargoGeo struct {
+	votos map[string]int
+	tipos map[string]urna.TipoVoto
+}
+
+// An area (município or local de votação) with the BU results of its seções.
+type areaGeo struct {
+	municipio urna.Municipio
+	zona      urna.NumeroZona
+	local     urna.NumeroLocal
+	porLocal  bool
+	secoes    int
+	cargos    map[string]*votosCargoGeo
+	ordem     []string // Cargos in the order first found.
+	localRecord
+}
+
+func (a *areaGeo) add(bu urna.EntidadeBoletimUrna, cargos []string) {
+	a.secoes++
+
+	for _, v := range urna.ListVotosBu(bu) {
+		if len(cargos) > 0 && !slices.ContainsFunc(cargos, func(c string) bool { return strings.EqualFold(c, v.Cargo) }) {
+			continue
+		}
+
+		c, ok := a.cargos[v.Cargo]
+		if !ok {
+			c = &votosCargoGeo{votos: make(map[string]int), tipos: make(map[string]urna.TipoVoto)}
+			a.cargos[v.Cargo] = c
+			a.ordem = append(a.ordem, v.Cargo)
+		}
+
+		votavel := v.TipoVoto.String()
+		if v.Codigo != 0 {
+			votavel = fmt.Sprint(v.Codigo)
+		}
+		c.votos[votavel] += v.Quantidade
+		c.tipos[votavel] = v.TipoVoto
+	}
+}
+
+// Feature properties: the area, then per cargo `<cargo>_total`,
+// `<cargo>_validos` (nominal and legenda votes), `<cargo>_vencedor` (the
+// nominal votável with most votes; on a tie, the tied ones comma-separated and
+// `<cargo>_empate` set), `<cargo>_<votavel>` and, for valid votes,
+// `<cargo>_<votavel>_pct` as a share of the valid votes.
+func (a *areaGeo) properties() map[string]interface{} {
+	p := map[string]interface{}{
+		"municipio": a.municipio.Nome,
+		"uf":        a.municipio.Uf,
+		"codigoTse": a.municipio.Id,
+		"secoes":    a.secoes,
+	}
+	if a.municipio.Ibge != 0 {
+		p["codigoIbge"] = a.municipio.Ibge
+	}
+	if a.porLocal {
+		p["zona"] = int(a.zona)
+		p["local"] = int(a.local)
+	}
+	if a.LocalNome != "" {
+		p["localNome"] = a.LocalNome
+		p["localEndereco"] = a.LocalEndereco
+		p["localBairro"] = a.LocalBairro
+	}
+
+	for _, cargo := range a.ordem {
+		c := a.cargos[cargo]
+
+		var total, validos int
+		var vencedores []string
+		for _, votavel := range sortCandidatos(maps.Keys(c.votos)) {
+			n := c.votos[votavel]
+			total += n
+
+			switch c.tipos[votavel] {
+			case urna.Nominal, urna.Legenda:
+				validos += n
+			}
+
+			if c.tipos[votavel] != urna.Nominal {
+				continue
+			}
+			switch {
+			case len(vencedores) == 0 || n > c.votos[vencedores[0]]:
+				vencedores = []string{votavel}
+			case n == c.votos[vencedores[0]]:
+				vencedores = append(vencedores, votavel)
+			}
+		}
+
+		p[cargo+"_total"] = total
+		p[cargo+"_validos"] = validos
+		if len(vencedores) > 0 {
+			p[cargo+"_vencedor"] = strings.Join(vencedores, ",")
+		}
+		if len(vencedores) > 1 {
+			p[cargo+"_empate"] = true
+		}
+
+		for votavel, n := range c.votos {
+			p[cargo+"_"+votavel] = n
+
+			switch c.tipos[votavel] {
+			case urna.Nominal, urna.Legenda:
+				if validos > 0 {
+					p[cargo+"_"+votavel+"_pct"] = float64(n) / float64(validos)
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+// Geometry of the area: the local's coordinates, or the feature of the
+// município in `geometrias` (by its 7-digit IBGE code, the IBGE code without
+// check digit, then the TSE code); nil if unknown.
+func (a *areaGeo) geometry(geometrias map[string]json.RawMessage) interface{} {
+	if a.porLocal {
+		if a.Latitude == 0 && a.Longitude == 0 {
+			return nil
+		}
+
+		return map[string]interface{}{
+			"type":        "Point",
+			"coordinates": []float64{a.Longitude, a.Latitude},
+		}
+	}
+
+	for _, k := range []int{a.municipio.Ibge, a.municipio.Ibge / 10, a.municipio.Id} {
+		g, ok := geometrias[strconv.Itoa(k)]
+		if k != 0 && ok {
+			return g
+		}
+	}
+
+	return nil
+}
+
+type geoFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoFeatureCollection struct {
+	Type     string       `json:"type"`
+	Features []geoFeature `json:"features"`
+}
+
+// Reads the geometries of a GeoJSON FeatureCollection by the value of `prop`.
+func readGeometrias(path, prop string) (map[string]json.RawMessage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fc struct {
+		Features []struct {
+			Geometry   json.RawMessage
+			Properties map[string]interface{}
+		}
+	}
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+	err = dec.Decode(&fc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	geometrias := make(map[string]json.RawMessage)
+	for _, feature := range fc.Features {
+		v, ok := feature.Properties[prop]
+		if !ok || v == nil {
+			continue
+		}
+
+		k := strings.TrimLeft(fmt.Sprint(v), "0")
+		geometrias[k] = feature.Geometry
+	}
+
+	if len(geometrias) == 0 {
+		return nil, fmt.Errorf("%s: no feature has property %s", path, prop)
+	}
+
+	return geometrias, nil
+}
+
+// Writes a GeoJSON FeatureCollection of the BU results per município or per
+// local de votação.
+func buToGeo(files []string) {
+	var geometrias map[string]json.RawMessage
+	if len(geometria) > 0 {
+		var err error
+		geometrias, err = readGeometrias(geometria, propriedade)
+		if err != nil {
+			inputError(err)
+			return
+		}
+	}
+
+	var cargos []string
+	if len(cargo) > 0 {
+		cargos = splitIntoSlice(cargo)
+	}
+
+	areas := make(map[chaveBuLocal]*areaGeo)
+	var ordem []chaveBuLocal
+
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		id := bu.IdentificacaoSecao
+		porLocal := geoPor == "local"
+		k := chaveBuLocal{municipio: id.MunicipioZona.Municipio}
+		if porLocal {
+			k.zona, k.local = id.MunicipioZona.Zona, id.Local
+		}
+
+		a, ok := areas[k]
+		if !ok {
+			a = &areaGeo{municipio: bu.Municipio(), zona: k.zona, local: k.local, porLocal: porLocal, cargos: make(map[string]*votosCargoGeo)}
+			if porLocal {
+				a.localRecord = localFor(id)
+			}
+			areas[k] = a
+			ordem = append(ordem, k)
+		}
+
+		a.add(bu, cargos)
+	})
+
+	slices.SortStableFunc(ordem, compareChaveBuLocal)
+
+	fc := geoFeatureCollection{Type: "FeatureCollection", Features: []geoFeature{}}
+	var semGeometria int
+	for _, k := range ordem {
+		a := areas[k]
+		g := a.geometry(geometrias)
+		if g == nil && geometrias != nil && !a.porLocal {
+			log.Printf("no geometry for %s (%s): IBGE code %d not in %s", a.municipio.Nome, a.municipio.Uf, a.municipio.Ibge, geometria)
+			semGeometria++
+		}
+
+		fc.Features = append(fc.Features, geoFeature{
+			Type:       "Feature",
+			Geometry:   g,
+			Properties: a.properties(),
+		})
+	}
+
+	// Most likely the wrong -propriedade or a geometry file of other codes.
+	if semGeometria > 0 && semGeometria*2 >= len(ordem) {
+		inputError(fmt.Errorf("%d of %d municípios without geometry in %s", semGeometria, len(ordem), geometria))
+		return
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	err := enc.Encode(fc)
+	if err != nil {
+		inputError(err)
+	}
+}
+
+func geoBuFlags() []string {
+	geoFlags := flag.NewFlagSet("geo", flag.ContinueOnError)
+	geoFlags.StringVar(&geoPor, "por", "municipio", "Aggregate per municipio or local (points from -locais)")
+	geoFlags.StringVar(&geometria, "geometria", "", "GeoJSON of município geometries to join (default no geometry)")
+	geoFlags.StringVar(&propriedade, "propriedade", "CD_MUN", "Property of -geometria features holding the IBGE (7 or 6 digits) or TSE código")
+	geoFlags.StringVar(&cargo, "cargo", "", "Comma-separated list (default all cargos)")
+
+	err := geoFlags.Parse(os.Args[3:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(geoFlags.Args()) == 0 || (geoPor != "municipio" && geoPor != "local") {
+		fmt.Println("usage: urna bu geo [-por <municipio|local>] [-geometria <file> [-propriedade <name>]] [-cargo <cargos>] <file_1> ... <file_n>")
+		geoFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return geoFlags.Args()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// One inspected file; `Tipo` is the detected extension (empty if unknown).
+type inspectRecord struct {
+	Arquivo  string             `json:"arquivo" yaml:"arquivo"`
+	Tipo     string             `json:"tipo" yaml:"tipo"`
+	Conteudo urna.InspectStruct `json:"conteudo" yaml:"conteudo"`
+}
+
+// Decoded files are nested, so the tabular formats do not apply.
+var inspectFormats = []string{"json", "ndjson", "yaml"}
+
+func Inspect() {
+	files := inspectFlags()
+
+	var records []inspectRecord
+	forEachFile(files, func(f string) {
+		if strings.HasSuffix(f, ".zip") {
+			err := urna.ProcessZipRaw(f, func(zf *zip.File) bool {
+				r, err := inspectZipFile(zf)
+				if errors.Is(err, urna.ErrUnknownFile) {
+					return false
+				}
+				if err != nil {
+					inputError(fmt.Errorf("%s: %w", zf.Name, err))
+					return stopped()
+				}
+
+				records = append(records, r)
+				return false
+			})
+			if err != nil {
+				inputError(err)
+			}
+			return
+		}
+
+		s, ext, err := urna.InspectFile(f)
+		if err != nil {
+			inputError(fmt.Errorf("%s: %w", f, err))
+			return
+		}
+
+		records = append(records, inspectRecord{f, ext, s})
+	})
+
+	var err error
+	switch format {
+	case "yaml":
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		for _, r := range records {
+			err = enc.Encode(r)
+			if err != nil {
+				break
+			}
+		}
+		enc.Close()
+	case "ndjson":
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range records {
+			err = enc.Encode(r)
+			if err != nil {
+				break
+			}
+		}
+	default:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if len(records) == 1 {
+			err = enc.Encode(records[0])
+		} else {
+			err = enc.Encode(records)
+		}
+	}
+
+	if err != nil {
+		inputError(err)
+	}
+}
+
+// Inspects a file inside a zip; files that are not ASN.1 (e.g. `*.logjez`) give urna.ErrUnknownFile.
+func inspectZipFile(zf *zip.File) (inspectRecord, error) {
+	data, err := urna.ReadZipFile(zf)
+	if err != nil {
+		return inspectRecord{}, err
+	}
+
+	ext, err := urna.DetectTipoArquivo(data)
+	if err != nil {
+		return inspectRecord{}, urna.ErrUnknownFile
+	}
+
+	s, _, err := urna.Inspect(data)
+	if err != nil {
+		return inspectRecord{}, err
+	}
+
+	return inspectRecord{zf.Name, ext, s}, nil
+}
+
+func inspectFlags() []string {
+	inspectFlags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	inspectFlags.StringVar(&format, "format", format, "Output format: "+strings.Join(inspectFormats, "|"))
+
+	err := inspectFlags.Parse(os.Args[2:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(inspectFlags.Args()) == 0 || (len(format) > 0 && !slices.Contains(inspectFormats, format)) {
+		fmt.Println("usage: urna inspect [-format <" + strings.Join(inspectFormats, "|") + ">] <file_1> ... <file_n>")
+		inspectFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return inspectFlags.Args()
+}
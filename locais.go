@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+	"golang.org/x/exp/slices"
+)
+
+var locais string
+var registroLocais *urna.RegistroLocais
+
+// Loads the `-locais` file; without it, outputs have no local columns.
+func useLocais() error {
+	registroLocais = nil
+	if len(locais) == 0 {
+		return nil
+	}
+
+	reg, err := urna.ReadRegistroLocais(locais)
+	if err != nil {
+		return err
+	}
+
+	registroLocais = reg
+	return nil
+}
+
+// Local de votação columns added to records when `-locais` is given.
+type localRecord struct {
+	LocalNome     string  `json:"localNome,omitempty"`
+	LocalEndereco string  `json:"localEndereco,omitempty"`
+	LocalBairro   string  `json:"localBairro,omitempty"`
+	Latitude      float64 `json:"latitude,omitempty"`
+	Longitude     float64 `json:"longitude,omitempty"`
+}
+
+// Header of the local columns; empty without `-locais`.
+func localHeader() []string {
+	if registroLocais == nil {
+		return nil
+	}
+
+	return []string{
+		"Local (nome)",
+		"Local (endereco)",
+		"Local (bairro)",
+		"Latitude",
+		"Longitude"}
+}
+
+func (r localRecord) row() []string {
+	if registroLocais == nil {
+		return nil
+	}
+
+	var lat, lon string
+	if r.Latitude != 0 || r.Longitude != 0 {
+		lat = strconv.FormatFloat(r.Latitude, 'f', -1, 64)
+		lon = strconv.FormatFloat(r.Longitude, 'f', -1, 64)
+	}
+
+	return []string{r.LocalNome, r.LocalEndereco, r.LocalBairro, lat, lon}
+}
+
+func newLocalRecord(l urna.LocalVotacao) localRecord {
+	return localRecord{
+		LocalNome:     l.Nome,
+		LocalEndereco: l.Endereco,
+		LocalBairro:   l.Bairro,
+		Latitude:      l.Latitude,
+		Longitude:     l.Longitude,
+	}
+}
+
+// Local of a seção; empty when unknown or without `-locais`.
+func localFor(id urna.IdentificacaoSecaoEleitoral) localRecord {
+	if registroLocais == nil {
+		return localRecord{}
+	}
+
+	l, _ := registroLocais.ForSecao(id)
+	return newLocalRecord(l)
+}
+
+// Local of the seção named by a TSE filename, by its local number if known
+// (zero otherwise).
+func localForFilename(path string, local urna.NumeroLocal) localRecord {
+	if registroLocais == nil {
+		return localRecord{}
+	}
+
+	s, err := urna.ParseSectionFilename(path)
+	if err != nil {
+		return localRecord{}
+	}
+
+	return localFor(urna.IdentificacaoSecaoEleitoral{
+		MunicipioZona: urna.MunicipioZona{Municipio: s.Municipio, Zona: s.Zona},
+		Local:         local,
+		Secao:         s.Secao,
+	})
+}
+
+// A verification result with the local of its seção.
+type verificationRecord struct {
+	urna.VerificationResult
+	localRecord
+}
+
+// JSON of a verificationRecord: the fields of urna.VerificationResult's JSON
+// followed by the local ones.
+type verificationRecordJSON struct {
+	Type      urna.VerificationResultType   `json:"type"`
+	Status    urna.VerificationResultStatus `json:"status"`
+	Error     string                        `json:"error,omitempty"`
+	Reason    string                        `json:"reason,omitempty"`
+	Filename  string                        `json:"filename,omitempty"`
+	Municipio string                        `json:"municipio"`
+	Zona      string                        `json:"zona"`
+	Secao     string                        `json:"secao"`
+	Payload   string                        `json:"payload,omitempty"`
+	localRecord
+}
+
+// The embedded result's MarshalJSON would otherwise drop the local fields.
+func (r verificationRecord) MarshalJSON() ([]byte, error) {
+	j := verificationRecordJSON{
+		Type:        r.Type,
+		Status:      r.Ok,
+		Reason:      r.Reason,
+		Filename:    r.Filename,
+		Municipio:   r.Municipio,
+		Zona:        r.Zona,
+		Secao:       r.Secao,
+		Payload:     r.PayloadString(),
+		localRecord: r.localRecord,
+	}
+	if r.Err != nil {
+		j.Error = r.Err.Error()
+	}
+
+	return json.Marshal(j)
+}
+
+// One `bu locais` record: the votes of one votável for one cargo of a local.
+type buLocalRecord struct {
+	Uf         string `json:"uf"`
+	Municipio  string `json:"municipio"`
+	Zona       int    `json:"zona"`
+	Local      int    `json:"local"`
+	Secoes     int    `json:"secoes"`
+	IdEleicao  int    `json:"idEleicao"`
+	Cargo      string `json:"cargo"`
+	TipoVoto   string `json:"tipoVoto"`
+	Partido    int    `json:"partido,omitempty"` // Empty for Branco and Nulo.
+	Votavel    int    `json:"votavel,omitempty"` // Empty for Branco and Nulo.
+	Quantidade int    `json:"quantidade"`
+	localRecord
+}
+
+type chaveBuLocal struct {
+	municipio urna.CodigoMunicipio
+	zona      urna.NumeroZona
+	local     urna.NumeroLocal
+}
+
+func compareChaveBuLocal(a, b chaveBuLocal) int {
+	switch {
+	case a.municipio != b.municipio:
+		return int(a.municipio - b.municipio)
+	case a.zona != b.zona:
+		return int(a.zona - b.zona)
+	default:
+		return int(a.local - b.local)
+	}
+}
+
+type chaveVotoLocal struct {
+	idEleicao int
+	cargo     string
+	tipoVoto  string
+	partido   int
+	votavel   int
+}
+
+// Sums the BU tuples of all seções of each local de votação.
+func buByLocal(files []string) {
+	var cargos []string
+	if len(cargo) > 0 {
+		cargos = splitIntoSlice(cargo)
+	}
+
+	type local struct {
+		record buLocalRecord
+		secoes map[urna.NumeroSecao]bool
+		votos  map[chaveVotoLocal]int
+		ordem  []chaveVotoLocal
+	}
+
+	byLocal := make(map[chaveBuLocal]*local)
+	var ordem []chaveBuLocal
+
+	forEachBu(files, func(bu urna.EntidadeBoletimUrna) {
+		id := bu.IdentificacaoSecao
+		k := chaveBuLocal{id.MunicipioZona.Municipio, id.MunicipioZona.Zona, id.Local}
+
+		l, ok := byLocal[k]
+		if !ok {
+			m := bu.Municipio()
+			l = &local{
+				record: buLocalRecord{
+					Uf:          m.Uf,
+					Municipio:   m.Nome,
+					Zona:        int(k.zona),
+					Local:       int(k.local),
+					localRecord: localFor(id),
+				},
+				secoes: make(map[urna.NumeroSecao]bool),
+				votos:  make(map[chaveVotoLocal]int),
+			}
+			byLocal[k] = l
+			ordem = append(ordem, k)
+		}
+		l.secoes[id.Secao] = true
+
+		for _, r := range buLongRecords(bu) {
+			if len(cargos) > 0 && !slices.ContainsFunc(cargos, func(c string) bool { return strings.EqualFold(c, r.Cargo) }) {
+				continue
+			}
+
+			v := chaveVotoLocal{r.IdEleicao, r.Cargo, r.TipoVoto, r.Partido, r.Votavel}
+			if _, ok := l.votos[v]; !ok {
+				l.ordem = append(l.ordem, v)
+			}
+			l.votos[v] += r.Quantidade
+		}
+	})
+
+	slices.SortStableFunc(ordem, compareChaveBuLocal)
+
+	w := newRecordWriter("csv", append([]string{
+		"UF",
+		"Municipio",
+		"Zona",
+		"Local",
+		"Secoes",
+		"ID eleicao",
+		"Cargo",
+		"Tipo voto",
+		"Partido",
+		"Votavel",
+		"Quantidade"}, localHeader()...))
+
+	for _, k := range ordem {
+		l := byLocal[k]
+		for _, v := range l.ordem {
+			r := l.record
+			r.Secoes = len(l.secoes)
+			r.IdEleicao, r.Cargo, r.TipoVoto, r.Partido, r.Votavel = v.idEleicao, v.cargo, v.tipoVoto, v.partido, v.votavel
+			r.Quantidade = l.votos[v]
+
+			w.Write(r, append([]string{
+				r.Uf,
+				r.Municipio,
+				fmt.Sprint(r.Zona),
+				fmt.Sprint(r.Local),
+				fmt.Sprint(r.Secoes),
+				fmt.Sprint(r.IdEleicao),
+				r.Cargo,
+				r.TipoVoto,
+				blankIfZero(r.Partido),
+				blankIfZero(r.Votavel),
+				fmt.Sprint(r.Quantidade)}, r.localRecord.row()...))
+		}
+	}
+
+	w.Close()
+}
+
+// An audit with the local of its seção.
+type auditRecord struct {
+	urna.AuditoriaSecao
+	localRecord
+}
+
+func localForAuditoria(a urna.AuditoriaSecao) localRecord {
+	local, _ := strconv.Atoi(a.Local)
+	return localForFilename(a.Filename, urna.NumeroLocal(local))
+}
@@ -1,14 +1,55 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
 )
 
 var cargo string
 var candidatos string
+var format string
+var keys string
+var deriveKeys string
+var ignoreAssinaturas bool
+var long bool
+var referencia string
 
 func main() {
+	os.Exit(run())
+}
+
+// Runs the command in os.Args and returns the process exit code.
+func run() int {
+	exitCode = exitOk
+
+	globalFlags := flag.NewFlagSet("urna", flag.ContinueOnError)
+	globalFlags.StringVar(&format, "format", "", "Output format: "+strings.Join(formats, "|")+" (default depends on the command)")
+	globalFlags.BoolVar(&failFast, "fail-fast", false, "Stop at the first verification failure or input error")
+	globalFlags.StringVar(&referencia, "referencia", "", "Reference data directory (municipios.csv, pleitos.csv, cargos.csv, or one such pack per election) taking precedence over the embedded packs")
+	globalFlags.StringVar(&locais, "locais", "", "CSV of locais de votação joined into BU, RDV and verification outputs")
+	filterFlags(globalFlags)
+
+	err := globalFlags.Parse(os.Args[1:])
+	if err != nil || (len(format) > 0 && !isValidFormat(format)) {
+		usage()
+		globalFlags.PrintDefaults()
+		return exitInputError
+	}
+
+	err = useReferencias()
+	if err == nil {
+		err = useLocais()
+	}
+	if err != nil {
+		fmt.Println(err)
+		return exitInputError
+	}
+	os.Args = append(os.Args[:1], globalFlags.Args()...)
+
 	var module string
 	if len(os.Args) > 1 {
 		module = os.Args[1]
@@ -21,8 +62,42 @@ func main() {
 		Vscmr()
 	case "rdv":
 		Rdv()
+	case "audit":
+		Audit()
+	case "inspect":
+		Inspect()
+	case "export":
+		Export()
+	case "serve":
+		Serve()
+	case "carga":
+		Carga()
 	default:
-		fmt.Println("usage: urna <bu|vscmr|rdv> <function> <options>")
-		fmt.Printf("provided module '%s' is none of (bu, vscmr, rdv)\n", module)
+		usage()
+		fmt.Printf("provided module '%s' is none of (bu, vscmr, rdv, audit, inspect, export, serve, carga)\n", module)
+		return exitInputError
 	}
+
+	return exitCode
+}
+
+// Loads the `-referencia` directory, or restores the embedded data.
+func useReferencias() error {
+	if len(referencia) == 0 {
+		urna.UseReferencias(nil)
+		return nil
+	}
+
+	refs, err := urna.LoadReferencias(referencia)
+	if err != nil {
+		return err
+	}
+
+	urna.UseReferencias(refs)
+	return nil
+}
+
+func usage() {
+	fmt.Println("usage: urna [-format <" + strings.Join(formats, "|") + ">] [-fail-fast] [-referencia <dir>] [-locais <csv>] [<filters>] <bu|vscmr|rdv|audit|inspect|export|serve|carga> <function> <options>")
+	fmt.Println("exit codes: 0 all ok, 1 verification failures, 2 unverifiable items, 3 input or decoding errors")
 }
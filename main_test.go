@@ -1,8 +1,26 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/certificate-transparency-go/asn1"
+	urna "github.com/mpbertram/urna/ue"
+	"github.com/parquet-go/parquet-go"
+	"gopkg.in/yaml.v3"
 )
 
 func TestVscmrVerify(t *testing.T) {
@@ -13,7 +31,7 @@ func TestVscmrVerify(t *testing.T) {
 
 	os.Args = []string{"", "vscmr", "verify", "ue/test-data/o00407-0100700090001.zip"}
 
-	main()
+	run()
 }
 
 func TestVscmrCsv(t *testing.T) {
@@ -24,7 +42,7 @@ func TestVscmrCsv(t *testing.T) {
 
 	os.Args = []string{"", "vscmr", "csv", "ue/test-data/o00407-0100700090001.zip"}
 
-	main()
+	run()
 }
 
 func TestVscmrCert(t *testing.T) {
@@ -35,7 +53,7 @@ func TestVscmrCert(t *testing.T) {
 
 	os.Args = []string{"", "vscmr", "cert", "ue/test-data/o00407-0100700090001.zip"}
 
-	main()
+	run()
 }
 
 func TestVscmrExport(t *testing.T) {
@@ -46,7 +64,7 @@ func TestVscmrExport(t *testing.T) {
 
 	os.Args = []string{"", "vscmr", "export", "ue/test-data/o00407-0100700090001.zip"}
 
-	main()
+	run()
 }
 
 func TestBuVerify(t *testing.T) {
@@ -56,10 +74,30 @@ func TestBuVerify(t *testing.T) {
 	}()
 
 	os.Args = []string{"", "bu", "verify", "ue/test-data/o00407-0100700090001.zip"}
-	main()
+	run()
 
 	os.Args = []string{"", "bu", "verify", "ue/test-data/urna.bu"}
-	main()
+	run()
+
+	os.Args = []string{"", "bu", "verify", "-derive-keys", "ue/test-data", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitInputError {
+		t.Error("expected keys not to be derived from the verified zip", c)
+	}
+
+	trusted := filepath.Join(t.TempDir(), "o00407-0100700090001.zip")
+	data, err := os.ReadFile("ue/test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(trusted, data, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"", "bu", "verify", "-derive-keys", trusted, "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitOk {
+		t.Error("expected key derived from a trusted copy", c)
+	}
 }
 
 func TestBuCount(t *testing.T) {
@@ -69,10 +107,10 @@ func TestBuCount(t *testing.T) {
 	}()
 
 	os.Args = []string{"", "bu", "count", "-cargo", "Presidente", "ue/test-data/o00407-0100700090001.zip"}
-	main()
+	run()
 
 	os.Args = []string{"", "bu", "count", "-cargo", "Presidente", "ue/test-data/urna.bu"}
-	main()
+	run()
 }
 
 func TestBuCsv(t *testing.T) {
@@ -82,10 +120,10 @@ func TestBuCsv(t *testing.T) {
 	}()
 
 	os.Args = []string{"", "bu", "csv", "-candidatos", "Nulo,Branco", "-cargo", "Presidente", "ue/test-data/o00407-0100700090001.zip"}
-	main()
+	run()
 
 	os.Args = []string{"", "bu", "csv", "-candidatos", "Nulo,Branco", "-cargo", "Presidente", "ue/test-data/urna.bu"}
-	main()
+	run()
 }
 
 func TestRdvCsv(t *testing.T) {
@@ -95,8 +133,721 @@ func TestRdvCsv(t *testing.T) {
 	}()
 
 	os.Args = []string{"", "rdv", "csv", "ue/test-data/o00407-0100700090001.zip"}
-	main()
+	run()
 
 	os.Args = []string{"", "rdv", "csv", "ue/test-data/urna.rdv"}
-	main()
+	run()
+}
+
+func TestAudit(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "audit", "-details", t.TempDir() + "/details.csv", "ue/test-data"}
+	run()
+
+	os.Args = []string{"", "audit", "ue/test-data/o00407-0100700090001.zip"}
+	c, out := runOutput(t)
+	if c != exitUnverifiable {
+		t.Error("expected exit code", exitUnverifiable, c)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil || len(rows) != 2 {
+		t.Fatal("expected one audited section", rows, err)
+	}
+
+	// Signed files the TSE does not publish are not failures.
+	audit := make(map[string]string)
+	for i, column := range rows[0] {
+		audit[column] = rows[1][i]
+	}
+	if audit["coverage"] != "unverifiable" || audit["Status"] != "unverifiable" || audit["Falhas"] != "0" {
+		t.Error("wrong audit", audit)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	for _, f := range formats {
+		os.Args = []string{"", "-format", f, "bu", "count", "-cargo", "Presidente", "ue/test-data/urna.bu"}
+		run()
+
+		os.Args = []string{"", "-format", f, "vscmr", "verify", "ue/test-data/o00407-0100700090001.zip"}
+		run()
+
+		os.Args = []string{"", "-format", f, "rdv", "csv", "ue/test-data/urna.rdv"}
+		run()
+	}
+
+	os.Args = []string{"", "-format", "yaml", "bu", "verify", "ue/test-data/urna.bu"}
+	_, out := runOutput(t)
+
+	var results []map[string]string
+	err := yaml.Unmarshal([]byte(out), &results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 || results[0]["municipio"] != "ACEGUA (RS)" || results[0]["status"] != "ok" {
+		t.Error("wrong yaml results", results)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "bu", "verify", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	// CEPESC signatures and signed files the TSE does not publish.
+	os.Args = []string{"", "vscmr", "verify", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitUnverifiable {
+		t.Error("expected exit code", exitUnverifiable, c)
+	}
+
+	os.Args = []string{"", "vscmr", "verify", "ue/test-data/missing.zip"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	os.Args = []string{"", "-fail-fast", "vscmr", "verify", "ue/test-data/missing.zip", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	os.Args = []string{"", "unknown"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	for _, module := range []string{"bu", "vscmr", "rdv", "export"} {
+		os.Args = []string{"", module, "foo"}
+		if c := run(); c != exitInputError {
+			t.Error("expected exit code", exitInputError, "for unknown function of", module, c)
+		}
+	}
+}
+
+func TestFailFastZip(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	data, err := os.ReadFile("ue/test-data/urna.bu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var eeg urna.EntidadeEnvelopeGenerico
+	_, err = asn1.Unmarshal(data, &eeg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eeg.Conteudo = []byte{0}
+	broken, err := asn1.Marshal(eeg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The broken BU comes first in the zip.
+	path := filepath.Join(t.TempDir(), "secoes.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for i, content := range [][]byte{broken, data} {
+		e, err := w.Create(fmt.Sprintf("%d.bu", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Write(content)
+	}
+	w.Close()
+	f.Close()
+
+	os.Args = []string{"", "bu", "csv", "-long", path}
+	if c, out := runOutput(t); c != exitInputError || strings.Count(out, "\n") < 2 {
+		t.Error("expected the valid BU after the broken one", c, out)
+	}
+
+	os.Args = []string{"", "-fail-fast", "bu", "csv", "-long", path}
+	if c, out := runOutput(t); c != exitInputError || strings.Count(out, "\n") != 1 {
+		t.Error("expected only the header in fail-fast mode", c, out)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "inspect", "ue/test-data/urna.bu", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "inspect", "-format", "yaml", "ue/test-data/urna.vscmr"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "-format", "yaml", "inspect", "ue/test-data/urna.vscmr"}
+	c, out := runOutput(t)
+	if c != exitOk || !strings.HasPrefix(out, "arquivo: ue/test-data/urna.vscmr\n") {
+		t.Error("expected yaml from the global -format", c, out)
+	}
+}
+
+func TestBuDiff(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "bu", "diff", "ue/test-data/urna.bu", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "bu", "diff", "-ignore-assinaturas", "ue/test-data/urna.bu", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitFailures {
+		t.Error("expected exit code", exitFailures, c)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "-uf", "SP", "vscmr", "verify", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitOk {
+		t.Error("expected filtered out section", c)
+	}
+
+	os.Args = []string{"", "-uf", "AC", "-fase", "oficial", "vscmr", "verify", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitUnverifiable {
+		t.Error("expected matching section", c)
+	}
+
+	os.Args = []string{"", "-fase", "simulado", "audit", "ue/test-data"}
+	if c := run(); c != exitOk {
+		t.Error("expected filtered out sections", c)
+	}
+
+	os.Args = []string{"", "-uf", "AC", "bu", "csv", "-long", "ue/test-data/urna.bu", "ue/test-data/o00407-0100700090001.zip"}
+	_, out := runOutput(t)
+	if !strings.Contains(out, "AC,BUJARI,") || strings.Contains(out, "ACEGUA") {
+		t.Error("expected only the BUJARI section", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "urna.db")
+	os.Args = []string{"", "-uf", "SP", "export", "sqlite", path, "ue/test-data/o00407-0100700090001.zip", "ue/test-data/urna.vscmr"}
+	run()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, table := range []string{"arquivo", "bu", "rdv", "verificacao", "certificado"} {
+		var n int
+		err = db.QueryRow("SELECT count(*) FROM " + table).Scan(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Error("expected filtered out rows in", table, n)
+		}
+	}
+
+	// Files filtered out before are not skipped as already loaded.
+	os.Args = []string{"", "-uf", "AC", "export", "sqlite", path, "ue/test-data/o00407-0100700090001.zip"}
+	run()
+
+	var n int
+	err = db.QueryRow("SELECT count(*) FROM bu").Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Error("expected the BU loaded with the second filter", n)
+	}
+}
+
+func TestBuCsvLong(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "bu", "csv", "-long", "ue/test-data/urna.bu", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "bu", "csv", "-long", "-cargo", "Presidente,Governador", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "bu", "csv", "-cargo", "Presidente", "ue/test-data/urna.bu", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+}
+
+func TestExportSqlite(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	path := filepath.Join(t.TempDir(), "urna.db")
+	for i := 0; i < 2; i++ {
+		os.Args = []string{"", "export", "sqlite", path, "ue/test-data/o00407-0100700090001.zip", "ue/test-data/urna.bu"}
+		run()
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for table, expected := range map[string]int{"arquivo": 2, "bu": 2} {
+		var n int
+		err = db.QueryRow("SELECT count(*) FROM " + table).Scan(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != expected {
+			t.Error("expected", expected, table, "rows, got", n)
+		}
+	}
+
+	var n int
+	err = db.QueryRow("SELECT count(*) FROM voto_bu").Scan(&n)
+	if err != nil || n == 0 {
+		t.Error("expected votes, got", n, err)
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	dir := t.TempDir()
+	os.Args = []string{"", "export", "parquet", dir, "ue/test-data/o00407-0100700090001.zip", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	secoes, err := filepath.Glob(filepath.Join(dir, "secao", "uf=*", "secao.parquet"))
+	if err != nil || len(secoes) != 2 {
+		t.Fatal("expected one secao partition per UF, got", secoes, err)
+	}
+
+	rows, err := parquet.ReadFile[secaoParquet](filepath.Join(dir, "secao", "uf=AC", "secao.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		if (r.Origem == "bu") != (r.DataHoraEmissao != 0) {
+			t.Error("expected data_hora_emissao only for BUs, got", r.Origem, r.DataHoraEmissao)
+		}
+		// 20221030T150247, Acre time (UTC-5).
+		if r.Origem == "bu" && r.DataHoraEmissao != time.Date(2022, 10, 30, 20, 2, 47, 0, time.UTC).UnixMilli() {
+			t.Error("wrong data_hora_emissao", time.UnixMilli(r.DataHoraEmissao).UTC())
+		}
+	}
+
+	rows, err = parquet.ReadFile[secaoParquet](filepath.Join(dir, "secao", "uf=RS", "secao.parquet"))
+	if err != nil || len(rows) != 1 {
+		t.Fatal("expected the RS section", rows, err)
+	}
+	// 20221002T170353, Brasília time (UTC-3).
+	if rows[0].DataHoraEmissao != time.Date(2022, 10, 2, 20, 3, 53, 0, time.UTC).UnixMilli() {
+		t.Error("wrong data_hora_emissao", time.UnixMilli(rows[0].DataHoraEmissao).UTC())
+	}
+
+	votos, err := parquet.ReadFile[votoBuParquet](filepath.Join(dir, "voto_bu", "uf=AC", "voto_bu.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(votos) == 0 {
+		t.Error("expected BU tuples")
+	}
+
+	rdv, err := parquet.ReadFile[votoRdvParquet](filepath.Join(dir, "voto_rdv", "uf=AC", "voto_rdv.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rdv) == 0 {
+		t.Error("expected RDV votes")
+	}
+}
+
+func TestServe(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	path := filepath.Join(t.TempDir(), "urna.db")
+	os.Args = []string{"", "export", "sqlite", path, "ue/test-data/o00407-0100700090001.zip"}
+	run()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	maxUpload = 64 << 20
+	srv := httptest.NewServer(newServeMux(db))
+	defer srv.Close()
+
+	upload := func(endpoint string, files ...string) *http.Response {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for _, f := range files {
+			fw, err := mw.CreateFormFile("file", filepath.Base(f))
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fw.Write(data)
+		}
+		mw.Close()
+
+		resp, err := http.Post(srv.URL+endpoint, mw.FormDataContentType(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := upload("/count", "ue/test-data/o00407-0100700090001.zip")
+	var votos []votosArquivo
+	json.NewDecoder(resp.Body).Decode(&votos)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(votos) != 2 || len(votos[0].Bu) == 0 || len(votos[1].Rdv) == 0 {
+		t.Error("expected BU and RDV votes, got", resp.Status, votos)
+	}
+
+	resp = upload("/verify", "ue/test-data/urna.vscmr", "ue/test-data/urna.bu")
+	var audits []urna.AuditoriaSecao
+	json.NewDecoder(resp.Body).Decode(&audits)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(audits) != 1 {
+		t.Error("expected one audit, got", resp.Status, audits)
+	}
+
+	resp = upload("/inspect", "ue/test-data/urna.bu")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Error("expected status 200, got", resp.Status)
+	}
+
+	resp = upload("/inspect", "ue/test-data/urna.bu", "ue/test-data/urna.bu")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("expected status 400 for a duplicate file name, got", resp.Status)
+	}
+
+	resp, err = http.Post(srv.URL+"/inspect?nome=x.bu", "application/octet-stream", strings.NewReader("not asn1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Error("expected status 422, got", resp.Status)
+	}
+
+	resp, err = http.Get(srv.URL + "/secoes/votos?uf=ac&zona=9&secao=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tuplas []buLongRecord
+	json.NewDecoder(resp.Body).Decode(&tuplas)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(tuplas) == 0 {
+		t.Error("expected BU tuples of the section, got", resp.Status, tuplas)
+	}
+
+	resp, err = http.Get(srv.URL + "/secoes?zona=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error("expected status 400, got", resp.Status)
+	}
+}
+
+func TestReferencia(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "-referencia", t.TempDir(), "bu", "count", "ue/test-data/urna.bu"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "municipios.csv"), []byte("1007,BUJARI,AC\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"", "-referencia", dir, "-uf", "ac", "audit", "ue/test-data/o00407-0100700090001.zip"}
+	if c := run(); c == exitInputError {
+		t.Error("unexpected exit code", c)
+	}
+}
+
+func TestLocais(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "-locais", filepath.Join(t.TempDir(), "locais.csv"), "bu", "locais", "ue/test-data/urna.bu"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	f := filepath.Join(t.TempDir(), "locais.csv")
+	err := os.WriteFile(f, []byte("municipio;zona;local;secao;nome;latitude;longitude\n1007;9;1104;1;ESCOLA A;-9,8;-67,9\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"", "-locais", f, "bu", "locais", "-cargo", "Presidente", "ue/test-data/o00407-0100700090001.zip"}
+	c, out := runOutput(t)
+	if c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 || rows[0][11] != "Local (nome)" {
+		t.Fatal("expected a header and 4 Presidente rows, got", rows)
+	}
+	for _, r := range rows[1:] {
+		if r[1] != "BUJARI" || r[3] != "1104" || r[4] != "1" || r[6] != "Presidente" || r[11] != "ESCOLA A" || r[14] != "-9.8" || r[15] != "-67.9" {
+			t.Error("unexpected row", r)
+		}
+	}
+	if rows[1][9] != "13" || rows[1][10] != "75" || rows[2][9] != "22" || rows[2][10] != "175" {
+		t.Error("unexpected votes", rows[1:3])
+	}
+
+	local := localFor(urna.IdentificacaoSecaoEleitoral{
+		MunicipioZona: urna.MunicipioZona{Municipio: 1007, Zona: 9},
+		Secao:         1,
+	})
+	if local.LocalNome != "ESCOLA A" || local.Latitude != -9.8 {
+		t.Error("unexpected local", local)
+	}
+
+	b, err := json.Marshal(verificationRecord{urna.VerificationResult{Type: urna.Signature}, local})
+	if err != nil || !strings.Contains(string(b), `"type":"signature"`) || !strings.Contains(string(b), `"localNome":"ESCOLA A"`) {
+		t.Error("unexpected json", string(b), err)
+	}
+
+	b, err = json.Marshal(verificationRecord{urna.VerificationResult{Type: urna.Signature}, localRecord{}})
+	if err != nil || strings.Contains(string(b), "local") {
+		t.Error("expected no local fields", string(b), err)
+	}
+}
+
+func TestBuGeo(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	f := filepath.Join(t.TempDir(), "municipios.geojson")
+	err := os.WriteFile(f, []byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-67.9,-9.8]},"properties":{"CD_MUN":"1200138"}}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"", "bu", "geo", "-geometria", f, "-cargo", "Presidente", "ue/test-data/o00407-0100700090001.zip"}
+	c, out := runOutput(t)
+	if c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	var fc struct {
+		Features []geoFeature
+	}
+	err = json.Unmarshal([]byte(out), &fc)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatal("expected one feature, got", out)
+	}
+	p := fc.Features[0].Properties
+	if fc.Features[0].Geometry == nil || p["codigoIbge"] != 1200138.0 || p["Presidente_vencedor"] != "22" || p["Presidente_total"] != 261.0 {
+		t.Error("expected BUJARI joined by IBGE code, got", fc.Features[0])
+	}
+
+	os.Args = []string{"", "bu", "geo", "-geometria", f, "-propriedade", "codigo", "ue/test-data/urna.bu"}
+	if c := run(); c != exitInputError {
+		t.Error("expected exit code", exitInputError, c)
+	}
+
+	// ACEGUA (RS) is not in the geometries: no mostly empty output.
+	os.Args = []string{"", "bu", "geo", "-geometria", f, "ue/test-data/urna.bu"}
+	if c, out := runOutput(t); c != exitInputError || len(out) > 0 {
+		t.Error("expected exit code", exitInputError, "without output, got", c, out)
+	}
+
+	a := &areaGeo{municipio: urna.Municipio{Id: 1007, Nome: "BUJARI", Uf: "AC"}, cargos: map[string]*votosCargoGeo{}}
+	a.ordem = []string{"Presidente"}
+	a.cargos["Presidente"] = &votosCargoGeo{
+		votos: map[string]int{"13": 30, "22": 30, "Branco": 40},
+		tipos: map[string]urna.TipoVoto{"13": urna.Nominal, "22": urna.Nominal, "Branco": urna.Branco},
+	}
+
+	p = a.properties()
+	if p["Presidente_total"] != 100 || p["Presidente_validos"] != 60 || p["Presidente_vencedor"] != "13,22" || p["Presidente_empate"] != true || p["Presidente_22_pct"] != 0.5 {
+		t.Error("unexpected properties", p)
+	}
+	if _, ok := p["Presidente_Branco_pct"]; ok {
+		t.Error("expected no share for Branco")
+	}
+
+	a.cargos["Presidente"].votos["22"] = 31
+	p = a.properties()
+	if p["Presidente_vencedor"] != "22" || p["Presidente_empate"] != nil {
+		t.Error("expected a single winner", p)
+	}
+
+	// Without an IBGE code, by TSE code.
+	tse := filepath.Join(t.TempDir(), "tse.geojson")
+	err = os.WriteFile(tse, []byte(`{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-67.9,-9.8]},"properties":{"CD_MUN":"01007"}}]}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geometrias, err := readGeometrias(tse, "CD_MUN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.geometry(geometrias) == nil {
+		t.Error("expected geometry joined by TSE code")
+	}
+}
+
+func TestBuUrnas(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "-format", "ndjson", "bu", "urnas", "ue/test-data/o00407-0100700090001.zip", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	os.Args = []string{"", "bu", "urnas", "-justificativa", "ue/test-data/urna.bu"}
+	if c := run(); c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	r := newUrnaRecord(urna.UrnaEnvelope{
+		Identificacao: urna.IdentificacaoMesaJustificativa{MunicipioZona: urna.MunicipioZona{Municipio: 1007, Zona: 9}, Mesa: 3, Urna: 4},
+	})
+	if r.Identificacao != "MesaJustificativa" || r.Mesa != 3 || r.Urna != 4 || r.Zona != 9 {
+		t.Error("unexpected record", r)
+	}
+}
+
+func TestCarga(t *testing.T) {
+	realArgs := os.Args
+	defer func() {
+		os.Args = realArgs
+	}()
+
+	os.Args = []string{"", "-format", "json", "carga", "ue/test-data/o00407-0100700090001.zip", "ue/test-data/urna.bu"}
+	c, out := runOutput(t)
+	if c != exitOk {
+		t.Error("expected exit code", exitOk, c)
+	}
+
+	var infos []urna.CargaInfo
+	err := json.Unmarshal([]byte(out), &infos)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+	if len(infos) != 2 {
+		t.Fatal("expected 2 sections, got", infos)
+	}
+
+	bujari, acegua := infos[0], infos[1]
+	if bujari.Municipio != "BUJARI (AC)" || bujari.NumeroInternoUrna != 1663883 || !bujari.DataHoraCarga.Equal(time.Date(2022, 9, 25, 15, 41, 0, 0, time.UTC)) {
+		t.Error("unexpected BUJARI carga", bujari)
+	}
+	if acegua.Municipio != "ACEGUA (RS)" || acegua.NumeroSerieFC != 121041845 || !acegua.DataHoraCarga.Equal(time.Date(2022, 9, 19, 14, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected ACEGUA carga", acegua)
+	}
+	for _, i := range infos {
+		if i.SecoesFlashCarga != 1 || i.UrnaReutilizada || i.FlashVotacaoReutilizada || i.Erro != "" {
+			t.Error("expected no reuse", i)
+		}
+	}
+}
+
+// Runs the command in os.Args and returns its exit code and standard output.
+func runOutput(t *testing.T) (int, string) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	c := run()
+	w.Close()
+
+	return c, <-out
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	urna "github.com/mpbertram/urna/ue"
+	"gopkg.in/yaml.v3"
+)
+
+var formats = []string{"csv", "json", "ndjson", "table", "yaml"}
+
+// Writes records to stdout in the format chosen with the global `-format`
+// option, or `defaultFormat` when none was given. JSON output is a single
+// array; NDJSON is one object per line; YAML is a sequence with the fields
+// of the JSON objects.
+type recordWriter struct {
+	format string
+	out    io.Writer
+	csv    *csv.Writer
+	table  *tabwriter.Writer
+	yaml   *yaml.Node
+	count  int
+}
+
+func newRecordWriter(defaultFormat string, header []string) *recordWriter {
+	w := &recordWriter{format: format, out: os.Stdout}
+	if len(w.format) == 0 {
+		w.format = defaultFormat
+	}
+
+	switch w.format {
+	case "csv":
+		w.csv = csv.NewWriter(w.out)
+		w.csv.Write(header)
+	case "table":
+		w.table = tabwriter.NewWriter(w.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w.table, strings.Join(header, "\t"))
+	case "json":
+		fmt.Fprint(w.out, "[")
+	case "yaml":
+		w.yaml = &yaml.Node{Kind: yaml.SequenceNode}
+	}
+
+	return w
+}
+
+// Writes `record` as JSON, or its `row` for the tabular formats.
+func (w *recordWriter) Write(record any, row []string) {
+	switch w.format {
+	case "csv":
+		w.csv.Write(row)
+	case "table":
+		fmt.Fprintln(w.table, strings.Join(row, "\t"))
+	case "json", "ndjson":
+		b, err := json.Marshal(record)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if w.format == "json" && w.count > 0 {
+			fmt.Fprint(w.out, ",")
+		}
+		if w.format == "json" {
+			fmt.Fprint(w.out, "\n  ")
+		}
+
+		w.out.Write(b)
+
+		if w.format == "ndjson" {
+			fmt.Fprintln(w.out)
+		}
+	case "yaml":
+		b, err := json.Marshal(record)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// JSON is YAML: the node keeps the keys and their order.
+		var n yaml.Node
+		err = yaml.Unmarshal(b, &n)
+		if err != nil {
+			log.Fatal(err)
+		}
+		w.yaml.Content = append(w.yaml.Content, blockStyle(n.Content[0]))
+	}
+
+	w.count++
+}
+
+func (w *recordWriter) Flush() {
+	switch w.format {
+	case "csv":
+		w.csv.Flush()
+	case "table":
+		w.table.Flush()
+	}
+}
+
+// Flushes and terminates the output; the writer must not be used afterwards.
+func (w *recordWriter) Close() {
+	w.Flush()
+
+	if w.format == "json" {
+		if w.count > 0 {
+			fmt.Fprintln(w.out)
+		}
+		fmt.Fprintln(w.out, "]")
+	}
+
+	if w.format == "yaml" {
+		enc := yaml.NewEncoder(w.out)
+		enc.SetIndent(2)
+		err := enc.Encode(w.yaml)
+		if err != nil {
+			log.Fatal(err)
+		}
+		enc.Close()
+	}
+}
+
+var verificationHeader = []string{
+	"Municipio",
+	"Zona",
+	"Secao",
+	"Arquivo",
+	"Tipo",
+	"Status",
+	"Erro",
+	"Motivo",
+	"Payload"}
+
+func verificationRow(r urna.VerificationResult) []string {
+	var e string
+	if r.Err != nil {
+		e = r.Err.Error()
+	}
+
+	return []string{
+		r.Municipio,
+		r.Zona,
+		r.Secao,
+		r.Filename,
+		r.Type.String(),
+		r.Ok.String(),
+		e,
+		r.Reason,
+		r.PayloadString()}
+}
+
+// Drops the flow style and quotes of a node parsed from JSON.
+func blockStyle(n *yaml.Node) *yaml.Node {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+
+	return n
+}
+
+func isValidFormat(f string) bool {
+	for _, valid := range formats {
+		if f == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,11 +1,8 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
-	"log"
 	"os"
-	"reflect"
 	"strings"
 
 	urna "github.com/mpbertram/urna/ue"
@@ -22,66 +19,90 @@ func Rdv() {
 		rdvToCsv(verifyRdvFlags())
 	default:
 		fmt.Println("usage: urna rdv csv <file_1> ... <file_n>")
+		fmt.Printf("provided function '%s' is none of (csv)\n", function)
+		setExitCode(exitInputError)
 	}
 }
 
-func rdvToCsv(files []string) {
-	w := csv.NewWriter(os.Stdout)
-	w.Write(
-		[]string{
-			"ID eleicao",
-			"Data geracao",
-			"Cargo",
-			"Quantidade escolhas",
-			"Tipo voto",
-			"Voto digitado"})
-
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+// One `rdv csv` record: a single vote as typed by the voter.
+type rdvCsvRecord struct {
+	IdEleicao          int    `json:"idEleicao"`
+	DataGeracao        string `json:"dataGeracao"`
+	Cargo              string `json:"cargo"`
+	QuantidadeEscolhas int    `json:"quantidadeEscolhas"`
+	TipoVoto           string `json:"tipoVoto"`
+	VotoDigitado       string `json:"votoDigitado"`
+	localRecord
+}
 
+func rdvToCsv(files []string) {
+	w := newRecordWriter("csv", append([]string{
+		"ID eleicao",
+		"Data geracao",
+		"Cargo",
+		"Quantidade escolhas",
+		"Tipo voto",
+		"Voto digitado"}, localHeader()...))
+
+	forEachSectionFile(files, func(f string) {
 		if strings.HasSuffix(f, ".rdv") {
 			rdv, err := urna.ReadRdv(f)
-
 			if err != nil {
-				log.Fatal("error reading RDV data ", err)
+				inputError(fmt.Errorf("error reading RDV data: %w", err))
+				return
 			}
 
 			processRdv(rdv, w)
 		}
 
 		if strings.HasSuffix(f, ".zip") {
-			urna.ProcessZip(f, func(rdv urna.EntidadeResultadoRDV) error {
+			err := urna.ProcessZip(f, func(rdv urna.EntidadeResultadoRDV) error {
+				if stopped() {
+					return nil
+				}
+
 				processRdv(rdv, w)
 				return nil
 			})
+			if err != nil {
+				inputError(err)
+			}
 		}
-	}
+	})
+
+	w.Close()
 }
 
-func processRdv(rdv urna.EntidadeResultadoRDV, w *csv.Writer) {
+func processRdv(rdv urna.EntidadeResultadoRDV, w *recordWriter) {
+	if !filtro.MatchRdv(rdv) {
+		return
+	}
+
 	el, err := rdv.Rdv.ReadEleicoes()
 	if err != nil {
-		log.Fatal("error reading Eleicoes ", err)
+		inputError(fmt.Errorf("error reading Eleicoes: %w", err))
+		return
 	}
 
-	if reflect.TypeOf(el) == reflect.TypeOf([]urna.EleicaoVota{}) {
-		for _, e := range el.([]urna.EleicaoVota) {
+	local := localFor(rdv.Rdv.Identificacao)
+
+	switch el := el.(type) {
+	case []urna.EleicaoVota:
+		for _, e := range el {
 			for _, vc := range e.VotosCargos {
-				processVotos(vc, w, e.IdEleicao, rdv.Cabecalho.DataGeracao)
+				processVotos(vc, w, e.IdEleicao, rdv.Cabecalho.DataGeracao, local)
 			}
 		}
-	}
-
-	if reflect.TypeOf(el) == reflect.TypeOf([]urna.EleicaoSA{}) {
-		for _, e := range el.([]urna.EleicaoSA) {
+	case []urna.EleicaoSA:
+		for _, e := range el {
 			for _, vc := range e.VotosCargos {
-				processVotos(vc, w, e.IdEleicao, rdv.Cabecalho.DataGeracao)
+				processVotos(vc, w, e.IdEleicao, rdv.Cabecalho.DataGeracao, local)
 			}
 		}
 	}
 }
 
-func processVotos(vc urna.VotosCargo, w *csv.Writer, id int, date urna.DataHoraJE) {
+func processVotos(vc urna.VotosCargo, w *recordWriter, id int, date urna.DataHoraJE, local localRecord) {
 	var cargo string
 	var escolhas int
 	var tipoVoto string
@@ -91,7 +112,8 @@ func processVotos(vc urna.VotosCargo, w *csv.Writer, id int, date urna.DataHoraJ
 
 	c, err := vc.ReadIdCargo()
 	if err != nil {
-		log.Fatal("error reading ID cargo ", err)
+		inputError(fmt.Errorf("error reading ID cargo: %w", err))
+		return
 	}
 
 	c, ok := c.(urna.CargoConstitucional)
@@ -104,20 +126,22 @@ func processVotos(vc urna.VotosCargo, w *csv.Writer, id int, date urna.DataHoraJ
 	for _, v := range vc.Votos {
 		votoDigitado = string(v.Digitacao)
 
-		tv, err := urna.TipoVotoRdvFromData(int(v.TipoVoto))
+		tv, err := urna.TipoVotoRdvFromEnumerated(v.TipoVoto)
 		if err != nil {
-			log.Fatal("error reading tipo voto ", err)
+			inputError(fmt.Errorf("error reading tipo voto: %w", err))
+			continue
 		}
 
 		tipoVoto = tv.String()
 
-		w.Write([]string{
-			fmt.Sprint(id),
-			string(date),
-			cargo,
-			fmt.Sprint(escolhas),
-			tipoVoto,
-			votoDigitado})
+		r := rdvCsvRecord{id, string(date), cargo, escolhas, tipoVoto, votoDigitado, local}
+		w.Write(r, append([]string{
+			fmt.Sprint(r.IdEleicao),
+			r.DataGeracao,
+			r.Cargo,
+			fmt.Sprint(r.QuantidadeEscolhas),
+			r.TipoVoto,
+			r.VotoDigitado}, r.localRecord.row()...))
 	}
 
 	w.Flush()
@@ -129,7 +153,7 @@ func verifyRdvFlags() []string {
 	}
 
 	fmt.Println("usage: urna rdv csv <file_1> ... <file_n>")
-	os.Exit(1)
+	os.Exit(exitInputError)
 
 	return []string{}
 }
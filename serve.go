@@ -0,0 +1,571 @@
+package main
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+var addr string
+var dbPath string
+var maxUpload int64
+
+// Serves the decoding, counting and verification of uploaded files and, with
+// `-db`, queries by section of a database created by `urna export sqlite`.
+func Serve() {
+	serveFlags()
+
+	var db *sql.DB
+	if len(dbPath) > 0 {
+		// sql.Open would create an empty database for a wrong path.
+		_, err := os.Stat(dbPath)
+		if err != nil {
+			inputError(err)
+			return
+		}
+
+		db, err = sql.Open("sqlite", dbPath)
+		if err != nil {
+			inputError(err)
+			return
+		}
+		defer db.Close()
+	}
+
+	log.Printf("listening on %s", addr)
+	err := http.ListenAndServe(addr, newServeMux(db))
+	if err != nil {
+		inputError(err)
+	}
+}
+
+// Routes of the server; the /secoes routes answer 404 without a database.
+//
+//	POST /inspect             decoded files, as `urna inspect`
+//	POST /count               BU tuples and RDV vote counts
+//	POST /verify              audit of each `*.zip` and `*.vscmr`, as `urna audit`
+//	GET  /secoes              sections with their BU
+//	GET  /secoes/votos        BU tuples of the sections
+//	GET  /secoes/verificacoes verification results of the sections
+//
+// Files are uploaded as the `file` fields of a multipart form (a `*.vscmr`
+// needs its `*.bu` or `*.rdv` in the same upload) or as the request body with
+// the name in `?nome=`. Sections are selected with the query parameters uf,
+// municipio (name or code), zona and secao.
+func newServeMux(db *sql.DB) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/inspect", uploadHandler(inspectUpload))
+	mux.HandleFunc("/count", uploadHandler(countUpload))
+	mux.HandleFunc("/verify", uploadHandler(verifyUpload))
+
+	if db != nil {
+		mux.HandleFunc("/secoes", queryHandler(db, querySecoes))
+		mux.HandleFunc("/secoes/votos", queryHandler(db, queryVotos))
+		mux.HandleFunc("/secoes/verificacoes", queryHandler(db, queryVerificacoes))
+	}
+
+	return mux
+}
+
+// Error of the client (bad request or undecodable file); other errors are answered with 500.
+type requestError struct {
+	err error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
+// Saves the uploaded files in a temporary directory and answers with the
+// result of `process` for their paths.
+func uploadHandler(process func(files []string) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		dir, err := os.MkdirTemp("", "urna-upload-")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer os.RemoveAll(dir)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+		files, err := saveUpload(r, dir)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		res, err := process(files)
+		var reqErr requestError
+		switch {
+		case errors.As(err, &reqErr):
+			writeError(w, http.StatusUnprocessableEntity, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			writeJSON(w, http.StatusOK, res)
+		}
+	}
+}
+
+func saveUpload(r *http.Request, dir string) ([]string, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		nome := r.URL.Query().Get("nome")
+		if len(nome) == 0 {
+			return nil, errors.New("missing ?nome= for the uploaded file")
+		}
+
+		f, err := saveFile(dir, nome, r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{f}, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if p.FormName() != "file" || len(p.FileName()) == 0 {
+			continue
+		}
+
+		f, err := saveFile(dir, p.FileName(), p)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no file uploaded")
+	}
+
+	return files, nil
+}
+
+// Keeps the base name, as section data is read from TSE filenames; files of
+// the same name are rejected instead of overwritten.
+func saveFile(dir, nome string, r io.Reader) (string, error) {
+	nome = filepath.Base(filepath.Clean("/" + nome))
+	if nome == "/" || nome == "." {
+		return "", fmt.Errorf("invalid file name %q", nome)
+	}
+
+	path := filepath.Join(dir, nome)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("duplicate file name %q", nome)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		return "", err
+	}
+
+	return path, f.Close()
+}
+
+func inspectUpload(files []string) (any, error) {
+	records := []inspectRecord{}
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".zip") {
+			s, ext, err := urna.InspectFile(f)
+			if err != nil {
+				return nil, requestError{fmt.Errorf("%s: %w", filepath.Base(f), err)}
+			}
+
+			records = append(records, inspectRecord{filepath.Base(f), ext, s})
+			continue
+		}
+
+		var inspectErr error
+		err := urna.ProcessZipRaw(f, func(zf *zip.File) bool {
+			r, err := inspectZipFile(zf)
+			if errors.Is(err, urna.ErrUnknownFile) {
+				return false
+			}
+			if err != nil {
+				inspectErr = fmt.Errorf("%s: %w", zf.Name, err)
+				return true
+			}
+
+			records = append(records, r)
+			return false
+		})
+		if err == nil {
+			err = inspectErr
+		}
+		if err != nil {
+			return nil, requestError{err}
+		}
+	}
+
+	return records, nil
+}
+
+// Vote count of an RDV, by eleição, cargo, tipo and digitação.
+type rdvContagem struct {
+	IdEleicao  int    `json:"idEleicao"`
+	Cargo      string `json:"cargo"`
+	TipoVoto   string `json:"tipoVoto"`
+	Digitacao  string `json:"digitacao,omitempty"`
+	Quantidade int    `json:"quantidade"`
+}
+
+// Votes of one uploaded file (or of a BU or RDV inside a zip).
+type votosArquivo struct {
+	Arquivo string         `json:"arquivo"`
+	Bu      []buLongRecord `json:"bu,omitempty"`
+	Rdv     []rdvContagem  `json:"rdv,omitempty"`
+}
+
+func countUpload(files []string) (any, error) {
+	votos := []votosArquivo{}
+	countBu := func(f string, bu urna.EntidadeBoletimUrna) {
+		votos = append(votos, votosArquivo{Arquivo: f, Bu: buLongRecords(bu)})
+	}
+	countRdv := func(f string, rdv urna.EntidadeResultadoRDV) error {
+		c, err := countRdvVotos(rdv)
+		if err == nil {
+			votos = append(votos, votosArquivo{Arquivo: f, Rdv: c})
+		}
+		return err
+	}
+
+	for _, f := range files {
+		nome := filepath.Base(f)
+
+		var err error
+		switch filepath.Ext(f) {
+		case ".zip":
+			err = urna.ProcessZip(f, func(eeg urna.EntidadeEnvelopeGenerico) error {
+				bu, err := eeg.ReadBu()
+				if err == nil {
+					countBu(nome, bu)
+				}
+				return err
+			})
+			if err == nil {
+				err = urna.ProcessZip(f, func(rdv urna.EntidadeResultadoRDV) error {
+					return countRdv(nome, rdv)
+				})
+			}
+		case ".bu":
+			var bu urna.EntidadeBoletimUrna
+			bu, err = urna.BuEntry{Path: f}.ReadBu()
+			if err == nil {
+				countBu(nome, bu)
+			}
+		case ".rdv":
+			var rdv urna.EntidadeResultadoRDV
+			rdv, err = urna.ReadRdv(f)
+			if err == nil {
+				err = countRdv(nome, rdv)
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, requestError{fmt.Errorf("%s: %w", nome, err)}
+		}
+	}
+
+	return votos, nil
+}
+
+// Counts the RDV votes in the order they first appear.
+func countRdvVotos(rdv urna.EntidadeResultadoRDV) ([]rdvContagem, error) {
+	votos, err := urna.ListVotosRdv(rdv)
+	if err != nil {
+		return nil, err
+	}
+
+	var contagens []rdvContagem
+	index := make(map[rdvContagem]int)
+	for _, v := range votos {
+		k := rdvContagem{IdEleicao: v.IdEleicao, Cargo: v.Cargo, TipoVoto: v.TipoVoto.String(), Digitacao: string(v.Digitacao)}
+		i, ok := index[k]
+		if !ok {
+			i = len(contagens)
+			index[k] = i
+			contagens = append(contagens, k)
+		}
+		contagens[i].Quantidade++
+	}
+
+	return contagens, nil
+}
+
+func verifyUpload(files []string) (any, error) {
+	audits := []urna.AuditoriaSecao{}
+	for _, f := range files {
+		var a urna.AuditoriaSecao
+		var err error
+		switch filepath.Ext(f) {
+		case ".zip":
+			a, err = urna.AuditZip(f)
+		case ".vscmr":
+			a, err = urna.AuditVscmr(f)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, requestError{fmt.Errorf("%s: %w", filepath.Base(f), err)}
+		}
+
+		a.Filename = filepath.Base(a.Filename)
+		audits = append(audits, a)
+	}
+
+	if len(audits) == 0 {
+		return nil, requestError{errors.New("no *.zip or *.vscmr uploaded")}
+	}
+
+	return audits, nil
+}
+
+// Answers GET requests with the result of `query` for the section parameters.
+func queryHandler(db *sql.DB, query func(db *sql.DB, where string, args []any) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		where, args, err := secaoWhere(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		res, err := query(db, where, args)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+// Builds the condition on the `secao` table `s` for the query parameters.
+func secaoWhere(r *http.Request) (string, []any, error) {
+	conds := []string{"1 = 1"}
+	var args []any
+
+	q := r.URL.Query()
+	if uf := q.Get("uf"); len(uf) > 0 {
+		conds = append(conds, "s.uf = ? COLLATE NOCASE")
+		args = append(args, uf)
+	}
+
+	if m := q.Get("municipio"); len(m) > 0 {
+		id, err := strconv.Atoi(m)
+		if err == nil {
+			conds = append(conds, "s.municipio = ?")
+			args = append(args, id)
+		} else {
+			conds = append(conds, "s.nome = ? COLLATE NOCASE")
+			args = append(args, m)
+		}
+	}
+
+	for _, p := range []string{"zona", "secao"} {
+		v := q.Get(p)
+		if len(v) == 0 {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid %s %q", p, v)
+		}
+		conds = append(conds, "s."+p+" = ?")
+		args = append(args, n)
+	}
+
+	return strings.Join(conds, " AND "), args, nil
+}
+
+type secaoJSON struct {
+	Uf              string `json:"uf"`
+	Municipio       int    `json:"municipio"`
+	Nome            string `json:"nome"`
+	Zona            int    `json:"zona"`
+	Local           int    `json:"local"`
+	Secao           int    `json:"secao"`
+	Fase            string `json:"fase,omitempty"`
+	TipoUrna        string `json:"tipoUrna,omitempty"`
+	TipoArquivo     string `json:"tipoArquivo,omitempty"`
+	Apuracao        string `json:"apuracao,omitempty"`
+	DataHoraEmissao string `json:"dataHoraEmissao,omitempty"`
+	Arquivo         string `json:"arquivo,omitempty"`
+}
+
+func querySecoes(db *sql.DB, where string, args []any) (any, error) {
+	rows, err := db.Query(`SELECT
+		COALESCE(s.uf, ''), s.municipio, COALESCE(s.nome, ''), s.zona, COALESCE(s.local, 0), s.secao,
+		COALESCE(b.fase, ''), COALESCE(u.tipo_urna, ''), COALESCE(b.tipo_arquivo, ''),
+		COALESCE(b.apuracao_tipo, ''), COALESCE(b.data_hora_emissao, ''), COALESCE(a.path, '')
+	FROM secao s
+	LEFT JOIN bu b ON b.secao_id = s.id
+	LEFT JOIN urna u ON u.id = b.urna_id
+	LEFT JOIN arquivo a ON a.id = b.arquivo_id
+	WHERE `+where+`
+	ORDER BY s.municipio, s.zona, s.secao`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	secoes := []secaoJSON{}
+	for rows.Next() {
+		var s secaoJSON
+		err := rows.Scan(&s.Uf, &s.Municipio, &s.Nome, &s.Zona, &s.Local, &s.Secao,
+			&s.Fase, &s.TipoUrna, &s.TipoArquivo, &s.Apuracao, &s.DataHoraEmissao, &s.Arquivo)
+		if err != nil {
+			return nil, err
+		}
+		secoes = append(secoes, s)
+	}
+
+	return secoes, rows.Err()
+}
+
+func queryVotos(db *sql.DB, where string, args []any) (any, error) {
+	rows, err := db.Query(`SELECT
+		COALESCE(s.uf, ''), COALESCE(s.nome, ''), s.zona, COALESCE(s.local, 0), s.secao,
+		v.id_eleicao, v.cargo, v.tipo_voto, COALESCE(v.partido, 0), COALESCE(v.votavel, 0), v.quantidade
+	FROM voto_bu v
+	JOIN bu b ON b.id = v.bu_id
+	JOIN secao s ON s.id = b.secao_id
+	WHERE `+where+`
+	ORDER BY s.municipio, s.zona, s.secao, v.rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votos := []buLongRecord{}
+	for rows.Next() {
+		var v buLongRecord
+		err := rows.Scan(&v.Uf, &v.Municipio, &v.Zona, &v.Local, &v.Secao,
+			&v.IdEleicao, &v.Cargo, &v.TipoVoto, &v.Partido, &v.Votavel, &v.Quantidade)
+		if err != nil {
+			return nil, err
+		}
+		votos = append(votos, v)
+	}
+
+	return votos, rows.Err()
+}
+
+type verificacaoJSON struct {
+	Filename  string `json:"filename"`
+	Municipio string `json:"municipio"`
+	Zona      string `json:"zona"`
+	Secao     string `json:"secao"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Results of the files that contained the BU of the sections.
+func queryVerificacoes(db *sql.DB, where string, args []any) (any, error) {
+	rows, err := db.Query(`SELECT
+		COALESCE(v.filename, ''), COALESCE(v.municipio, ''), COALESCE(v.zona, ''), COALESCE(v.secao, ''),
+		v.tipo, v.status, COALESCE(v.erro, ''), COALESCE(v.motivo, '')
+	FROM verificacao v
+	WHERE v.arquivo_id IN (
+		SELECT b.arquivo_id FROM bu b JOIN secao s ON s.id = b.secao_id WHERE `+where+`
+	)
+	ORDER BY v.rowid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	verificacoes := []verificacaoJSON{}
+	for rows.Next() {
+		var v verificacaoJSON
+		err := rows.Scan(&v.Filename, &v.Municipio, &v.Zona, &v.Secao, &v.Type, &v.Status, &v.Error, &v.Reason)
+		if err != nil {
+			return nil, err
+		}
+		verificacoes = append(verificacoes, v)
+	}
+
+	return verificacoes, rows.Err()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Erro string `json:"erro"`
+	}{err.Error()})
+}
+
+func serveFlags() {
+	serveFlags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	serveFlags.StringVar(&addr, "addr", "localhost:8080", "Address to listen on")
+	serveFlags.StringVar(&dbPath, "db", "", "SQLite database created by `urna export sqlite` for the /secoes queries")
+	serveFlags.Int64Var(&maxUpload, "max-upload", 64<<20, "Maximum size of an upload in bytes")
+
+	err := serveFlags.Parse(os.Args[2:])
+	if err != nil || len(serveFlags.Args()) > 0 {
+		fmt.Println("usage: urna serve [-addr <host:port>] [-db <db>] [-max-upload <bytes>]")
+		serveFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"archive/zip"
+	"log"
+	"os"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+// Process exit codes; when several apply, the most severe one wins
+// (input error, then failures, then unverifiable).
+const (
+	exitOk           = 0 // Everything verified.
+	exitFailures     = 1 // At least one verification failed.
+	exitUnverifiable = 2 // No failures, but some items could not be verified.
+	exitInputError   = 3 // Some input could not be read or decoded (also used for usage errors).
+)
+
+var exitCode = exitOk
+var failFast bool
+
+func severity(code int) int {
+	switch code {
+	case exitUnverifiable:
+		return 1
+	case exitFailures:
+		return 2
+	case exitInputError:
+		return 3
+	default:
+		return 0
+	}
+}
+
+func setExitCode(code int) {
+	if severity(code) > severity(exitCode) {
+		exitCode = code
+	}
+}
+
+func trackResults(results []urna.VerificationResult) {
+	for _, r := range results {
+		switch r.Ok {
+		case urna.Nok:
+			setExitCode(exitFailures)
+		case urna.Unverifiable:
+			setExitCode(exitUnverifiable)
+		}
+	}
+}
+
+func inputError(err error) {
+	log.Println(err)
+	setExitCode(exitInputError)
+}
+
+// In -fail-fast mode, processing stops after the first failure or input error;
+// checked between files and between the entries of a zip.
+func stopped() bool {
+	return failFast && severity(exitCode) >= severity(exitFailures)
+}
+
+// Calls `process` for every readable file (zips and `*.vscmr` are opened to
+// check them) that matches the filter, stopping early in -fail-fast mode.
+func forEachFile(files []string, process func(f string)) {
+	eachFile(files, matchFilter, process)
+}
+
+// Like forEachFile, but only the filename is filtered: for commands that
+// decode the sections themselves and match them with filtro.MatchBu and
+// filtro.MatchRdv, so that zips are not decoded twice.
+func forEachSectionFile(files []string, process func(f string)) {
+	eachFile(files, filtro.MatchFilename, process)
+}
+
+func eachFile(files []string, match func(f string) bool, process func(f string)) {
+	for _, f := range files {
+		if stopped() {
+			log.Printf("stopping at %s (fail fast)", f)
+			return
+		}
+
+		_, err := os.Stat(f)
+		if err != nil {
+			inputError(err)
+			continue
+		}
+
+		if !match(f) {
+			continue
+		}
+
+		log.Printf("processing file %s", f)
+
+		if strings.HasSuffix(f, ".zip") {
+			r, err := zip.OpenReader(f)
+			if err != nil {
+				inputError(err)
+				continue
+			}
+			r.Close()
+		}
+
+		if strings.HasSuffix(f, ".vscmr") {
+			_, err := urna.ReadAssinatura(f)
+			if err != nil {
+				inputError(err)
+				continue
+			}
+		}
+
+		process(f)
+	}
+}
@@ -2,10 +2,9 @@ package ue
 
 import (
 	"archive/zip"
-	"bytes"
-	"crypto/sha512"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,6 +13,7 @@ import (
 	"github.com/google/certificate-transparency-go/asn1"
 
 	"golang.org/x/exp/slices"
+	"golang.org/x/text/encoding/charmap"
 )
 
 func ReadAssinatura(file string) (EntidadeAssinaturaResultado, error) {
@@ -31,22 +31,42 @@ func ReadAssinatura(file string) (EntidadeAssinaturaResultado, error) {
 	return a, nil
 }
 
-type VerificationResultStatus bool
+// Outcome of a single check; Unverifiable means the check could not be
+// performed at all (e.g. no certificate to verify a signature against).
+type VerificationResultStatus uint8
 
 const (
-	Ok  VerificationResultStatus = true
-	Nok VerificationResultStatus = false
+	Nok          VerificationResultStatus = 0
+	Ok           VerificationResultStatus = 1
+	Unverifiable VerificationResultStatus = 2
 )
 
 func (s VerificationResultStatus) String() string {
 	switch s {
 	case Ok:
 		return "ok"
+	case Unverifiable:
+		return "unverifiable"
 	default:
 		return "nok"
 	}
 }
 
+func (s VerificationResultStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *VerificationResultStatus) UnmarshalText(text []byte) error {
+	for _, status := range []VerificationResultStatus{Nok, Ok, Unverifiable} {
+		if status.String() == string(text) {
+			*s = status
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid verification status %q", text)
+}
+
 type VerificationResultType uint8
 
 const (
@@ -54,6 +74,9 @@ const (
 	Signature   VerificationResultType = 1
 	Payload     VerificationResultType = 2
 	Certificate VerificationResultType = 3
+	Coverage    VerificationResultType = 4
+	Key         VerificationResultType = 5
+	Consistency VerificationResultType = 6
 )
 
 func (t VerificationResultType) String() string {
@@ -66,15 +89,37 @@ func (t VerificationResultType) String() string {
 		return "payload"
 	case Certificate:
 		return "cert"
+	case Coverage:
+		return "coverage"
+	case Key:
+		return "key"
+	case Consistency:
+		return "consistency"
 	default:
 		return ""
 	}
 }
 
+func (t VerificationResultType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *VerificationResultType) UnmarshalText(text []byte) error {
+	for _, typ := range []VerificationResultType{Hash, Signature, Payload, Certificate, Coverage, Key, Consistency} {
+		if typ.String() == string(text) {
+			*t = typ
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid verification type %q", text)
+}
+
 type VerificationResult struct {
 	Type      VerificationResultType
 	Ok        VerificationResultStatus
 	Err       error
+	Reason    string // Why the check could not be performed (only for Unverifiable).
 	Filename  string
 	Municipio string
 	Zona      string
@@ -82,7 +127,94 @@ type VerificationResult struct {
 	Payload   []byte
 }
 
+// JSON representation of a VerificationResult; field names are part of the output schema.
+type verificationResultJSON struct {
+	Type      VerificationResultType   `json:"type"`
+	Status    VerificationResultStatus `json:"status"`
+	Error     string                   `json:"error,omitempty"`
+	Reason    string                   `json:"reason,omitempty"`
+	Filename  string                   `json:"filename,omitempty"`
+	Municipio string                   `json:"municipio"`
+	Zona      string                   `json:"zona"`
+	Secao     string                   `json:"secao"`
+	Payload   string                   `json:"payload,omitempty"`
+}
+
+func (r VerificationResult) MarshalJSON() ([]byte, error) {
+	j := verificationResultJSON{
+		Type:      r.Type,
+		Status:    r.Ok,
+		Reason:    r.Reason,
+		Filename:  r.Filename,
+		Municipio: r.Municipio,
+		Zona:      r.Zona,
+		Secao:     r.Secao,
+		Payload:   r.PayloadString(),
+	}
+	if r.Err != nil {
+		j.Error = r.Err.Error()
+	}
+
+	return json.Marshal(j)
+}
+
+func (r *VerificationResult) UnmarshalJSON(data []byte) error {
+	var j verificationResultJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+
+	*r = VerificationResult{
+		Type:      j.Type,
+		Ok:        j.Status,
+		Reason:    j.Reason,
+		Filename:  j.Filename,
+		Municipio: j.Municipio,
+		Zona:      j.Zona,
+		Secao:     j.Secao,
+	}
+	if len(j.Error) > 0 {
+		r.Err = errors.New(j.Error)
+	}
+	if len(j.Payload) > 0 {
+		r.Payload, err = charmap.ISO8859_1.NewEncoder().Bytes([]byte(j.Payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Payload as text; BU payloads are ISO-8859-1 encoded.
+func (r VerificationResult) PayloadString() string {
+	if r.Payload == nil {
+		return ""
+	}
+
+	s, err := charmap.ISO8859_1.NewDecoder().Bytes(r.Payload)
+	if err != nil {
+		return string(r.Payload)
+	}
+
+	return string(s)
+}
+
 func (r VerificationResult) Msg() string {
+	if r.Ok == Unverifiable {
+		return fmt.Sprintf(
+			"[%s] [%s] file=%s municipio=%s, zona=%s, secao=%s, reason=%s",
+			r.Ok.String(),
+			r.Type.String(),
+			r.Filename,
+			r.Municipio,
+			r.Zona,
+			r.Secao,
+			r.Reason,
+		)
+	}
+
 	if r.Payload != nil {
 		return fmt.Sprintf(
 			"[%s] [%s] municipio=%s, zona=%s, secao=%s, payload=%s",
@@ -123,17 +255,21 @@ func ExportCertsZip(path string) {
 	})
 }
 
+// Named after the section, e.g. `BUJARI_(AC)_9_1_<criação>.cer`, or after the
+// file when its name has no section.
+func certificateFilename(path string, criacao DataHoraJE) string {
+	municipio, zona, secao := sectionFields(path)
+	if len(municipio) == 0 {
+		name := filepath.Base(path)
+		return strings.TrimSuffix(name, filepath.Ext(name)) + "_" + string(criacao) + ".cer"
+	}
+
+	return strings.ReplaceAll(municipio, " ", "_") + "_" + zona + "_" + secao + "_" + string(criacao) + ".cer"
+}
+
 func exportCertificate(path string, data EntidadeAssinatura) {
 	if len(data.CertificadoDigital) > 0 {
-		err := os.WriteFile(
-			strings.ReplaceAll(MunicipioByFile(path), " ", "_")+
-				"_"+ZonaByFile(path)+
-				"_"+SecaoByFile(path)+
-				"_"+string(data.DataHoraCriacao)+
-				".cer",
-			data.CertificadoDigital,
-			0644,
-		)
+		err := os.WriteFile(certificateFilename(path, data.DataHoraCriacao), data.CertificadoDigital, 0644)
 
 		if err != nil {
 			log.Print(err)
@@ -192,6 +328,21 @@ func VerifyAssinaturaVscmr(path string) []VerificationResult {
 	results = append(results, verifyAssinaturaVscmr(path, a.AssinaturaHW)...)
 	results = append(results, verifyAssinaturaVscmr(path, a.AssinaturaSW)...)
 
+	// A directory may hold several sections: only the files sharing the base
+	// name of the signature are its own.
+	var present []string
+	dirEntries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		log.Println(err)
+	}
+	for _, e := range dirEntries {
+		if !e.IsDir() && stem(e.Name()) == stem(path) {
+			present = append(present, e.Name())
+		}
+	}
+
+	results = append(results, verifyCoverage(filepath.Base(path), present, a)...)
+
 	return results
 }
 
@@ -211,7 +362,7 @@ func verifyAssinaturaVscmr(path string, sig EntidadeAssinatura) []VerificationRe
 			continue
 		}
 
-		results = append(results, verifyHash(file, arquivo.Assinatura.Hash, arquivo.NomeArquivo))
+		results = append(results, verifyHash(file, arquivo.Assinatura.Hash, sig.AutoAssinado.AlgoritmoHash, arquivo.NomeArquivo))
 		results = append(results, verifySignature(sig, arquivo))
 	}
 
@@ -224,17 +375,123 @@ func VerifyAssinaturaZip(path string) []VerificationResult {
 	ProcessZip(path, func(e EntidadeAssinaturaResultado, ctx ZipProcessCtx) {
 		results = append(results, verifyAssinaturaZip(ctx, e.AssinaturaHW)...)
 		results = append(results, verifyAssinaturaZip(ctx, e.AssinaturaSW)...)
+
+		// Every entry of the zip must be signed, except the files of other
+		// sections with a signature of their own.
+		var entries, others []string
+		ProcessZipRaw(ctx.ZipFilename, func(f *zip.File) bool {
+			if f.FileInfo().IsDir() {
+				return false
+			}
+
+			entries = append(entries, f.Name)
+			if filepath.Ext(f.Name) == ".vscmr" && filepath.Base(f.Name) != ctx.Filename {
+				others = append(others, stem(f.Name))
+			}
+			return false
+		})
+
+		var present []string
+		for _, entry := range entries {
+			if !slices.Contains(others, stem(entry)) {
+				present = append(present, entry)
+			}
+		}
+
+		results = append(results, verifyCoverage(ctx.Filename, present, e)...)
 	})
 
 	return results
 }
 
+var (
+	ErrUnsignedFile      = errors.New("file is not signed")
+	ErrMissingFile       = errors.New("signed file is missing")
+	ErrSignatureMismatch = errors.New("file is not signed by both HW and SW")
+)
+
+// Checks that the files `present` besides `sigFilename` are exactly the ones
+// signed, and that HW and SW signatures list the same files. Signed files that
+// are not present are unverifiable rather than wrong: the TSE does not publish
+// all of them (e.g. `*.jufa`, `*.wsqbio` or `*.chvtp`).
+func verifyCoverage(sigFilename string, present []string, e EntidadeAssinaturaResultado) []VerificationResult {
+	hw := signedFiles(e.AssinaturaHW)
+	sw := signedFiles(e.AssinaturaSW)
+
+	var files []string
+	for _, p := range present {
+		name := filepath.Base(p)
+		if name != filepath.Base(sigFilename) && !slices.Contains(files, name) {
+			files = append(files, name)
+		}
+	}
+
+	for _, name := range append(hw, sw...) {
+		if !slices.Contains(files, name) {
+			files = append(files, name)
+		}
+	}
+
+	var results []VerificationResult
+	for _, name := range files {
+		isPresent := slices.ContainsFunc(present, func(p string) bool { return filepath.Base(p) == name })
+		inHw := slices.Contains(hw, name)
+		inSw := slices.Contains(sw, name)
+
+		ok := true
+		if !inHw && !inSw {
+			results = append(results, newCoverageError(ErrUnsignedFile, name))
+			continue
+		}
+		if !inHw {
+			results = append(results, newCoverageError(fmt.Errorf("%w: missing from HW", ErrSignatureMismatch), name))
+			ok = false
+		}
+		if !inSw {
+			results = append(results, newCoverageError(fmt.Errorf("%w: missing from SW", ErrSignatureMismatch), name))
+			ok = false
+		}
+		if !ok {
+			continue
+		}
+
+		if isPresent {
+			results = append(results, newCoverageOk(name))
+		} else {
+			results = append(results, newCoverageUnverifiable(ErrMissingFile.Error(), name))
+		}
+	}
+
+	return results
+}
+
+// File name without its extension.
+func stem(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func signedFiles(sig EntidadeAssinatura) []string {
+	conteudoAssinado, err := sig.ReadConteudoAssinado()
+	if err != nil {
+		log.Println(err)
+		return []string{}
+	}
+
+	var names []string
+	for _, arquivo := range conteudoAssinado.ArquivosAssinados {
+		names = append(names, arquivo.NomeArquivo)
+	}
+
+	return names
+}
+
 func verifyAssinaturaZip(ctx ZipProcessCtx, sig EntidadeAssinatura) []VerificationResult {
 	var results []VerificationResult
 
 	conteudoAssinado, err := sig.ReadConteudoAssinado()
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
 	}
 
 	results = append(results, verifyAutoContent(sig, ctx.Filename)...)
@@ -243,19 +500,17 @@ func verifyAssinaturaZip(ctx ZipProcessCtx, sig EntidadeAssinatura) []Verificati
 	ProcessZipRaw(ctx.ZipFilename, func(f *zip.File) bool {
 		for _, arquivo := range conteudoAssinado.ArquivosAssinados {
 			if f.Name == arquivo.NomeArquivo {
-				rc, err := f.Open()
+				data, err := ReadZipFile(f)
 				if err != nil {
-					log.Fatal(err)
+					results = append(results, newHashUnverifiable(err.Error(), arquivo.NomeArquivo))
+					count++
+					continue
 				}
 
-				var buf bytes.Buffer
-				io.Copy(&buf, rc)
-
-				results = append(results, verifyHash(buf.Bytes(), arquivo.Assinatura.Hash, arquivo.NomeArquivo))
+				results = append(results, verifyHash(data, arquivo.Assinatura.Hash, sig.AutoAssinado.AlgoritmoHash, arquivo.NomeArquivo))
 				results = append(results, verifySignature(sig, arquivo))
 
 				count++
-				rc.Close()
 			}
 		}
 
@@ -272,14 +527,26 @@ func verifyAssinaturaZip(ctx ZipProcessCtx, sig EntidadeAssinatura) []Verificati
 
 func verifyAutoContent(sig EntidadeAssinatura, filename string) []VerificationResult {
 	var results []VerificationResult
-	results = append(results, verifyHash(sig.ConteudoAutoAssinado, sig.AutoAssinado.Assinatura.Hash, filename))
+	results = append(results, verifyHash(sig.ConteudoAutoAssinado, sig.AutoAssinado.Assinatura.Hash, sig.AutoAssinado.AlgoritmoHash, filename))
 	results = append(results, verifyAutoSignature(sig, filename))
 	return results
 }
 
-func verifyHash(content []byte, hash []byte, filename string) VerificationResult {
-	checksum := sha512.Sum512(content)
-	if !slices.Equal(checksum[:], hash) {
+// Hashes `content` with the algorithm declared in the signature (files are signed with the same one).
+func verifyHash(content []byte, hash []byte, alg AlgoritmoHashInfo, filename string) VerificationResult {
+	algHash, err := AlgoritmoHashFromEnumerated(alg.Algoritmo)
+	if err != nil {
+		return newHashUnverifiable(fmt.Sprintf("%s: hash algorithm %d", ErrUnsupportedAlgorithm, alg.Algoritmo), filename)
+	}
+
+	hashType, err := algHash.GetHashFunction()
+	if err != nil {
+		return newHashUnverifiable(err.Error(), filename)
+	}
+
+	h := hashType.New()
+	h.Write(content)
+	if !slices.Equal(h.Sum(nil), hash) {
 		return newHashError(filename)
 	}
 
@@ -288,10 +555,11 @@ func verifyHash(content []byte, hash []byte, filename string) VerificationResult
 
 func verifyAutoSignature(assinatura EntidadeAssinatura, filename string) VerificationResult {
 	err := assinatura.VerifyAutoSignature()
+	if errors.Is(err, ErrNoCertificate) || errors.Is(err, ErrUnsupportedAlgorithm) {
+		return newSigUnverifiable(err.Error(), filename)
+	}
 	if err != nil {
-		if err.Error() != "no certificate" {
-			return newSigError(err, filename)
-		}
+		return newSigError(err, filename)
 	}
 
 	return newSigOk(filename)
@@ -299,79 +567,80 @@ func verifyAutoSignature(assinatura EntidadeAssinatura, filename string) Verific
 
 func verifySignature(assinatura EntidadeAssinatura, arquivo AssinaturaArquivo) VerificationResult {
 	err := assinatura.VerifySignature(arquivo)
+	if errors.Is(err, ErrNoCertificate) || errors.Is(err, ErrUnsupportedAlgorithm) {
+		return newSigUnverifiable(err.Error(), arquivo.NomeArquivo)
+	}
 	if err != nil {
-		if err.Error() != "no certificate" {
-			return newSigError(err, arquivo.NomeArquivo)
-		}
+		return newSigError(err, arquivo.NomeArquivo)
 	}
 
 	return newSigOk(arquivo.NomeArquivo)
 }
 
-func newSigOk(filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Signature,
-		Ok:        Ok,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
+// Result of a check on `filename`, with the section read from its name.
+func newResult(t VerificationResultType, status VerificationResultStatus, filename string) VerificationResult {
+	r := VerificationResult{
+		Type:     t,
+		Ok:       status,
+		Filename: filename,
 	}
+	r.Municipio, r.Zona, r.Secao = sectionFields(filename)
+
+	return r
+}
+
+func newSigOk(filename string) VerificationResult {
+	return newResult(Signature, Ok, filename)
 }
 
 func newSigError(err error, filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Signature,
-		Ok:        Nok,
-		Err:       err,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
-	}
+	r := newResult(Signature, Nok, filename)
+	r.Err = err
+	return r
+}
+
+func newSigUnverifiable(reason string, filename string) VerificationResult {
+	r := newResult(Signature, Unverifiable, filename)
+	r.Reason = reason
+	return r
 }
 
 func newHashOk(filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Hash,
-		Ok:        Ok,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
-	}
+	return newResult(Hash, Ok, filename)
+}
+
+func newHashUnverifiable(reason string, filename string) VerificationResult {
+	r := newResult(Hash, Unverifiable, filename)
+	r.Reason = reason
+	return r
 }
 
 func newHashError(filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Hash,
-		Ok:        Nok,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
-	}
+	return newResult(Hash, Nok, filename)
+}
+
+func newCoverageOk(filename string) VerificationResult {
+	return newResult(Coverage, Ok, filename)
+}
+
+func newCoverageUnverifiable(reason string, filename string) VerificationResult {
+	r := newResult(Coverage, Unverifiable, filename)
+	r.Reason = reason
+	return r
+}
+
+func newCoverageError(err error, filename string) VerificationResult {
+	r := newResult(Coverage, Nok, filename)
+	r.Err = err
+	return r
 }
 
 func newCertOk(filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Certificate,
-		Ok:        Ok,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
-	}
+	return newResult(Certificate, Ok, filename)
 }
 
 func newCertError(err error, filename string) VerificationResult {
-	return VerificationResult{
-		Type:      Certificate,
-		Ok:        Nok,
-		Err:       err,
-		Filename:  filename,
-		Municipio: MunicipioByFile(filename),
-		Zona:      ZonaByFile(filename),
-		Secao:     SecaoByFile(filename),
-	}
+	r := newResult(Certificate, Nok, filename)
+	r.Err = err
+	return r
 }
@@ -1,7 +1,23 @@
 package ue
 
 import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	x509native "crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/google/certificate-transparency-go/asn1"
 )
 
 func TestAssinaturaZip(t *testing.T) {
@@ -24,3 +40,238 @@ func TestAssinatura(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestAssinaturaNoCertificate(t *testing.T) {
+	vscmr, err := ReadAssinatura("test-data/urna.vscmr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := vscmr.AssinaturaHW
+	sig.CertificadoDigital = nil
+
+	r := verifyAutoSignature(sig, "o00407-0100700090001.vscmr")
+	if r.Ok != Unverifiable || r.Reason != ErrNoCertificate.Error() {
+		t.Error("expected unverifiable signature without certificate", r.Ok, r.Reason)
+	}
+}
+
+func TestVerifyHash(t *testing.T) {
+	content := []byte("urna")
+	sum := sha256.Sum256(content)
+
+	r := verifyHash(content, sum[:], AlgoritmoHashInfo{Algoritmo: asn1.Enumerated(Sha256)}, "o00407-0100700090001.bu")
+	if r.Ok != Ok {
+		t.Error("expected sha256 hash to verify")
+	}
+
+	r = verifyHash(content, sum[:], AlgoritmoHashInfo{Algoritmo: asn1.Enumerated(Sha512)}, "o00407-0100700090001.bu")
+	if r.Ok != Nok {
+		t.Error("expected sha512 hash to fail")
+	}
+
+	r = verifyHash(content, sum[:], AlgoritmoHashInfo{Algoritmo: 9}, "o00407-0100700090001.bu")
+	if r.Ok != Unverifiable {
+		t.Error("expected unknown hash algorithm to be unverifiable")
+	}
+}
+
+func TestAssinaturaUnverifiable(t *testing.T) {
+	vscmr, err := ReadAssinatura("test-data/urna.vscmr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := verifyAutoSignature(vscmr.AssinaturaSW, "o00407-0100700090001.vscmr")
+	if r.Ok != Unverifiable {
+		t.Error("expected unverifiable SW signature, got", r.Ok)
+	}
+	if r.Reason != "unsupported algorithm: CEPESC" {
+		t.Error("wrong reason", r.Reason)
+	}
+
+	r = verifyAutoSignature(vscmr.AssinaturaHW, "o00407-0100700090001.vscmr")
+	if r.Ok != Ok {
+		t.Error("expected ok HW signature, got", r.Ok, r.Err)
+	}
+}
+
+func TestAssinaturaRsa(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509native.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "urna"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509native.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := []byte("conteudo assinado")
+	signed := sha256.Sum256(hash)
+	assinatura, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, signed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := EntidadeAssinatura{
+		AutoAssinado: AutoAssinaturaDigital{
+			AlgoritmoHash:       AlgoritmoHashInfo{Algoritmo: asn1.Enumerated(Sha256)},
+			AlgoritmoAssinatura: AlgoritmoAssinaturaInfo{Algoritmo: asn1.Enumerated(Rsa), Bits: 2048},
+			Assinatura:          AssinaturaDigital{Hash: hash, Assinatura: assinatura},
+		},
+		CertificadoDigital: cert,
+	}
+
+	r := verifyAutoSignature(sig, "o00407-0100700090001.vscmr")
+	if r.Ok != Ok {
+		t.Error("expected ok RSA signature, got", r.Ok, r.Err)
+	}
+
+	sig.AutoAssinado.Assinatura.Hash = []byte("outro conteudo")
+	r = verifyAutoSignature(sig, "o00407-0100700090001.vscmr")
+	if r.Ok != Nok {
+		t.Error("expected failed RSA signature, got", r.Ok, r.Err)
+	}
+
+	// An ECDSA certificate does not verify an RSA signature.
+	vscmr, err := ReadAssinatura("test-data/urna.vscmr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := vscmr.AssinaturaHW
+	hw.AutoAssinado.AlgoritmoAssinatura.Algoritmo = asn1.Enumerated(Rsa)
+	r = verifyAutoSignature(hw, "o00407-0100700090001.vscmr")
+	if r.Ok != Nok {
+		t.Error("expected failed RSA signature with an ECDSA certificate, got", r.Ok, r.Err)
+	}
+}
+
+func TestAssinaturaCoverage(t *testing.T) {
+	var ok, missing int
+	for _, r := range VerifyAssinaturaZip("test-data/o00407-0100700090001.zip") {
+		if r.Type != Coverage {
+			continue
+		}
+
+		switch r.Ok {
+		case Ok:
+			ok++
+		case Unverifiable:
+			if r.Reason != ErrMissingFile.Error() {
+				t.Error("wrong reason", r.Reason)
+			}
+			missing++
+		case Nok:
+			t.Error(r.Msg(), r.Err)
+		}
+	}
+
+	// bu, imgbu, logjez and rdv are published; the remaining signed files are not.
+	if ok != 4 {
+		t.Error("wrong number of covered files", ok)
+	}
+	if missing != 7 {
+		t.Error("wrong number of missing files", missing)
+	}
+
+	var unsigned int
+	missing = 0
+	for _, r := range VerifyAssinaturaVscmr("test-data/urna.vscmr") {
+		if r.Type != Coverage {
+			continue
+		}
+
+		switch {
+		case errors.Is(r.Err, ErrUnsignedFile):
+			unsigned++
+		case r.Ok == Unverifiable:
+			missing++
+		}
+	}
+
+	if unsigned != 2 {
+		t.Error("wrong number of unsigned files", unsigned)
+	}
+	if missing == 0 {
+		t.Error("expected missing files")
+	}
+}
+
+func TestAssinaturaCoverageExtraFile(t *testing.T) {
+	r, err := zip.OpenReader("test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	path := filepath.Join(t.TempDir(), "o00407-0100700090001.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := zip.NewWriter(f)
+	for _, entry := range r.File {
+		err = w.Copy(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	evil, err := w.Create("evil.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	evil.Write([]byte("evil"))
+	w.Close()
+	f.Close()
+
+	var unsigned []string
+	for _, r := range VerifyAssinaturaZip(path) {
+		if r.Type == Coverage && errors.Is(r.Err, ErrUnsignedFile) {
+			unsigned = append(unsigned, r.Filename)
+		}
+	}
+
+	if len(unsigned) != 1 || unsigned[0] != "evil.txt" {
+		t.Error("expected evil.txt to be unsigned", unsigned)
+	}
+}
+
+func TestVerificationResultJSON(t *testing.T) {
+	r := VerificationResult{
+		Type:      Payload,
+		Ok:        Nok,
+		Err:       errors.New("failure"),
+		Municipio: "BUJARI (AC)",
+		Zona:      "9",
+		Secao:     "1",
+		Payload:   []byte{'1', 0xe7},
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"payload","status":"nok","error":"failure","municipio":"BUJARI (AC)","zona":"9","secao":"1","payload":"1ç"}`
+	if string(b) != expected {
+		t.Error("wrong json", string(b))
+	}
+
+	var decoded VerificationResult
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Type != r.Type || decoded.Ok != r.Ok || decoded.Err.Error() != r.Err.Error() || !bytes.Equal(decoded.Payload, r.Payload) {
+		t.Error("wrong round trip", decoded)
+	}
+}
@@ -0,0 +1,214 @@
+package ue
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var ErrInconsistentVotos = errors.New("bu and rdv vote counts differ")
+
+// Result of all checks run against one section.
+type AuditoriaSecao struct {
+	Filename  string                   `json:"filename"` // `*.zip` or `*.vscmr` the section was read from.
+	Municipio string                   `json:"municipio"`
+	Zona      string                   `json:"zona"`
+	Secao     string                   `json:"secao"`
+	Local     string                   `json:"local,omitempty"` // Only known from the BU.
+	Status    VerificationResultStatus `json:"status"`          // Nok if any check failed, else Unverifiable if any could not be run.
+	Results   []VerificationResult     `json:"results"`         // Detailed per-check results.
+}
+
+// Status of each type of check, combined the same way as the section verdict.
+func (a AuditoriaSecao) StatusByType() map[VerificationResultType]VerificationResultStatus {
+	status := make(map[VerificationResultType]VerificationResultStatus)
+	for _, r := range a.Results {
+		s, ok := status[r.Type]
+		if !ok {
+			s = Ok
+		}
+		status[r.Type] = worst(s, r.Ok)
+	}
+
+	return status
+}
+
+// Audits a section zip as published by the TSE (one section per zip).
+// The audit is returned even when some of its files could not be read.
+func AuditZip(path string) (AuditoriaSecao, error) {
+	a := AuditoriaSecao{Filename: path}
+
+	_, release, err := openZipReader(path)
+	if err != nil {
+		a.audit(nil, nil)
+		return a, err
+	}
+	release()
+
+	a.Results = append(a.Results, VerifyAssinaturaZip(path)...)
+	a.Results = append(a.Results, VerifyCertsZip(path)...)
+
+	var bus []EntidadeBoletimUrna
+	var readErr error
+	err = ProcessZip(path, func(eeg EntidadeEnvelopeGenerico) error {
+		bu, err := eeg.ReadBu()
+		if err != nil {
+			log.Println(err)
+			readErr = err
+			return err
+		}
+
+		bus = append(bus, bu)
+		return nil
+	})
+	if err != nil {
+		readErr = err
+	}
+
+	var rdvs []EntidadeResultadoRDV
+	err = ProcessZip(path, func(rdv EntidadeResultadoRDV) error {
+		rdvs = append(rdvs, rdv)
+		return nil
+	})
+	if err != nil {
+		readErr = err
+	}
+
+	a.audit(bus, rdvs)
+
+	return a, readErr
+}
+
+// Audits a `*.vscmr` together with the `*.bu` and `*.rdv` sharing its base name.
+// A missing `*.bu` or `*.rdv` is not an error (the consistency check is reported as unverifiable).
+func AuditVscmr(path string) (AuditoriaSecao, error) {
+	a := AuditoriaSecao{Filename: path}
+
+	_, err := ReadAssinatura(path)
+	if err != nil {
+		a.audit(nil, nil)
+		return a, err
+	}
+
+	a.Results = append(a.Results, VerifyAssinaturaVscmr(path)...)
+	a.Results = append(a.Results, VerifyCertsVscmr(path)...)
+
+	stem := strings.TrimSuffix(path, filepath.Ext(path))
+
+	var readErr error
+
+	var bus []EntidadeBoletimUrna
+	bu, err := BuEntry{Path: stem + EntidadeEnvelopeGenerico{}.Extension()}.ReadBu()
+	if err == nil {
+		bus = append(bus, bu)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		readErr = err
+	}
+
+	var rdvs []EntidadeResultadoRDV
+	rdv, err := ReadRdv(stem + EntidadeResultadoRDV{}.Extension())
+	if err == nil {
+		rdvs = append(rdvs, rdv)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		readErr = err
+	}
+
+	a.audit(bus, rdvs)
+
+	return a, readErr
+}
+
+func (a *AuditoriaSecao) audit(bus []EntidadeBoletimUrna, rdvs []EntidadeResultadoRDV) {
+	a.Municipio, a.Zona, a.Secao = sectionFields(a.Filename)
+
+	for _, bu := range bus {
+		a.Municipio = bu.Municipio().String()
+		a.Zona = fmt.Sprint(bu.IdentificacaoSecao.MunicipioZona.Zona)
+		a.Secao = fmt.Sprint(bu.IdentificacaoSecao.Secao)
+		a.Local = fmt.Sprint(bu.IdentificacaoSecao.Local)
+
+		a.Results = append(a.Results, ValidateVotosBu(bu)...)
+	}
+
+	switch {
+	case len(bus) == 0:
+		a.Results = append(a.Results, a.newConsistencyResult(Unverifiable, nil, "no bu"))
+	case len(rdvs) == 0:
+		a.Results = append(a.Results, a.newConsistencyResult(Unverifiable, nil, "no rdv"))
+	default:
+		for _, bu := range bus {
+			for _, rdv := range rdvs {
+				a.Results = append(a.Results, a.verifyBuRdv(bu, rdv))
+			}
+		}
+	}
+
+	a.Status = Ok
+	if len(a.Results) == 0 {
+		a.Status = Unverifiable
+	}
+	for _, r := range a.Results {
+		a.Status = worst(a.Status, r.Ok)
+	}
+}
+
+// Compares the BU totals with the votes recorded in the RDV, for every cargo constitucional.
+func (a *AuditoriaSecao) verifyBuRdv(bu EntidadeBoletimUrna, rdv EntidadeResultadoRDV) VerificationResult {
+	cargos := ValidCargoConstitucional()
+
+	votosBu := CountVotosBu(bu, cargos)
+	votosRdv, err := CountVotosRdv(rdv, cargos)
+	if err != nil {
+		return a.newConsistencyResult(Unverifiable, nil, err.Error())
+	}
+
+	var diffs []string
+	for _, cargo := range cargos {
+		candidatos := append(maps.Keys(votosBu[cargo]), maps.Keys(votosRdv[cargo])...)
+		slices.Sort(candidatos)
+		candidatos = slices.Compact(candidatos)
+
+		for _, candidato := range candidatos {
+			if votosBu[cargo][candidato] != votosRdv[cargo][candidato] {
+				diffs = append(diffs, fmt.Sprintf(
+					"%s/%s: bu=%d rdv=%d", cargo, candidato, votosBu[cargo][candidato], votosRdv[cargo][candidato]))
+			}
+		}
+	}
+
+	if len(diffs) > 0 {
+		return a.newConsistencyResult(Nok, fmt.Errorf("%w: %s", ErrInconsistentVotos, strings.Join(diffs, ", ")), "")
+	}
+
+	return a.newConsistencyResult(Ok, nil, "")
+}
+
+func (a *AuditoriaSecao) newConsistencyResult(status VerificationResultStatus, err error, reason string) VerificationResult {
+	return VerificationResult{
+		Type:      Consistency,
+		Ok:        status,
+		Err:       err,
+		Reason:    reason,
+		Filename:  filepath.Base(a.Filename),
+		Municipio: a.Municipio,
+		Zona:      a.Zona,
+		Secao:     a.Secao,
+	}
+}
+
+func worst(a VerificationResultStatus, b VerificationResultStatus) VerificationResultStatus {
+	if a == Nok || b == Nok {
+		return Nok
+	}
+	if a == Unverifiable || b == Unverifiable {
+		return Unverifiable
+	}
+
+	return Ok
+}
@@ -0,0 +1,51 @@
+package ue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAuditZip(t *testing.T) {
+	a, err := AuditZip("test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Municipio != "BUJARI (AC)" || a.Zona != "9" || a.Secao != "1" {
+		t.Error("wrong section", a.Municipio, a.Zona, a.Secao)
+	}
+
+	status := a.StatusByType()
+	for _, check := range []VerificationResultType{Hash, Certificate, Payload, Consistency} {
+		if status[check] != Ok {
+			t.Error("expected ok", check, status[check])
+		}
+	}
+	if status[Signature] != Unverifiable {
+		t.Error("expected unverifiable CEPESC signatures", status[Signature])
+	}
+	if status[Coverage] != Unverifiable {
+		t.Error("expected unverifiable coverage of unpublished files", status[Coverage])
+	}
+	if a.Status != Unverifiable {
+		t.Error("expected unverifiable section", a.Status)
+	}
+}
+
+func TestAuditVscmr(t *testing.T) {
+	a, err := AuditVscmr("test-data/urna.vscmr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inconsistent bool
+	for _, r := range a.Results {
+		if errors.Is(r.Err, ErrInconsistentVotos) {
+			inconsistent = true
+		}
+	}
+
+	// urna.bu and urna.rdv come from different sections.
+	if !inconsistent || a.Status != Nok {
+		t.Error("expected inconsistent bu and rdv", a.Status)
+	}
+}
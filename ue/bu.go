@@ -117,6 +117,47 @@ func CountVotosBu(b EntidadeBoletimUrna, cargos []CargoConstitucional) map[Cargo
 	return votosPorCargo
 }
 
+// Votes of a votável (or Branco/Nulo) for a cargo, as stored in the BU.
+type VotosVotavel struct {
+	IdEleicao  IDEleicao
+	Cargo      string // CargoConstitucional name or consulta number.
+	TipoVoto   TipoVoto
+	Partido    NumeroPartido // Zero for Branco and Nulo.
+	Codigo     NumeroVotavel // Zero for Branco and Nulo.
+	Quantidade int
+	Assinatura []byte // Signature of the tuple with the BU key.
+}
+
+// Lists the votes of all cargos of the BU in file order.
+func ListVotosBu(b EntidadeBoletimUrna) []VotosVotavel {
+	var votos []VotosVotavel
+	for _, votacaoPorEleicao := range b.ResultadosVotacaoPorEleicao {
+		for _, votacao := range votacaoPorEleicao.ResultadosVotacao {
+			for _, votoCargo := range votacao.TotaisVotosCargo {
+				cargo := fmt.Sprintf("%x", votoCargo.CodigoCargo.FullBytes)
+				c, err := votoCargo.ReadCodigoCargo()
+				if err == nil {
+					cargo = fmt.Sprint(c)
+				}
+
+				for _, votoVotavel := range votoCargo.VotosVotaveis {
+					votos = append(votos, VotosVotavel{
+						IdEleicao:  votacaoPorEleicao.IdEleicao,
+						Cargo:      cargo,
+						TipoVoto:   TipoVoto(votoVotavel.TipoVoto),
+						Partido:    votoVotavel.IdentificacaoVotavel.Partido,
+						Codigo:     votoVotavel.IdentificacaoVotavel.Codigo,
+						Quantidade: votoVotavel.QuantidadeVotos,
+						Assinatura: votoVotavel.Assinatura,
+					})
+				}
+			}
+		}
+	}
+
+	return votos
+}
+
 func ValidateVotosBu(b EntidadeBoletimUrna) []VerificationResult {
 	var results []VerificationResult
 
@@ -134,17 +175,17 @@ func ValidateVotosBu(b EntidadeBoletimUrna) []VerificationResult {
 						results = append(results, VerificationResult{
 							Type:      Payload,
 							Ok:        Nok,
-							Municipio: b.IdentificacaoSecao.Municipio().String(),
-							Zona:      fmt.Sprint(b.IdentificacaoSecao.Local),
+							Municipio: b.Municipio().String(),
+							Zona:      fmt.Sprint(b.IdentificacaoSecao.MunicipioZona.Zona),
 							Secao:     fmt.Sprint(b.IdentificacaoSecao.Secao),
 							Payload:   payload,
 						})
 					} else {
 						results = append(results, VerificationResult{
 							Type:      Payload,
-							Ok:        true,
-							Municipio: b.IdentificacaoSecao.Municipio().String(),
-							Zona:      fmt.Sprint(b.IdentificacaoSecao.Local),
+							Ok:        Ok,
+							Municipio: b.Municipio().String(),
+							Zona:      fmt.Sprint(b.IdentificacaoSecao.MunicipioZona.Zona),
 							Secao:     fmt.Sprint(b.IdentificacaoSecao.Secao),
 							Payload:   payload,
 						})
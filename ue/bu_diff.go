@@ -0,0 +1,120 @@
+package ue
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Field-level difference between two BUs; A or B is empty when the field is absent there.
+type DiferencaBu struct {
+	Campo string `json:"campo"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// Compares header, urna/carga data, section identification, emission times
+// and per-cargo votes of two BUs; signature bytes (tuple signatures and the
+// BU key) can be ignored.
+func DiffBu(a, b EntidadeBoletimUrna, ignoreAssinaturas bool) []DiferencaBu {
+	camposA, valoresA := flattenBu(a, ignoreAssinaturas)
+	camposB, valoresB := flattenBu(b, ignoreAssinaturas)
+
+	campos := camposA
+	for _, c := range camposB {
+		if _, ok := valoresA[c]; !ok {
+			campos = append(campos, c)
+		}
+	}
+
+	var diferencas []DiferencaBu
+	for _, c := range campos {
+		if valoresA[c] != valoresB[c] {
+			diferencas = append(diferencas, DiferencaBu{c, valoresA[c], valoresB[c]})
+		}
+	}
+
+	return diferencas
+}
+
+// Returns the fields of a BU in order, with votes keyed by eleição, cargo and votável.
+func flattenBu(b EntidadeBoletimUrna, ignoreAssinaturas bool) ([]string, map[string]string) {
+	var campos []string
+	valores := make(map[string]string)
+	add := func(campo string, valor any) {
+		campos = append(campos, campo)
+		valores[campo] = fmt.Sprint(valor)
+	}
+
+	for _, f := range inspectStruct(reflect.ValueOf(b), false) {
+		switch f.Name {
+		case "ResultadosVotacaoPorEleicao":
+			continue
+		case "ChaveAssinaturaVotosVotavel":
+			if ignoreAssinaturas {
+				continue
+			}
+		}
+
+		flattenInspect(f.Name, f.Value, add)
+	}
+
+	for _, rve := range b.ResultadosVotacaoPorEleicao {
+		eleicao := fmt.Sprintf("Eleicao %d", rve.IdEleicao)
+		add(eleicao+".QtdEleitoresAptos", rve.QtdEleitoresAptos)
+
+		for _, rv := range rve.ResultadosVotacao {
+			tipoCargo := fmt.Sprint(rv.TipoCargo)
+			t, err := TipoCargoConsultaFromEnumerated(rv.TipoCargo)
+			if err == nil {
+				tipoCargo = t.String()
+			}
+			add(eleicao+"."+tipoCargo+".QtdComparecimento", rv.QtdComparecimento)
+
+			for _, vc := range rv.TotaisVotosCargo {
+				cargo := fmt.Sprintf("%x", vc.CodigoCargo.FullBytes)
+				c, err := vc.ReadCodigoCargo()
+				if err == nil {
+					cargo = fmt.Sprint(c)
+				}
+				cargo = eleicao + "." + cargo
+
+				add(cargo+".OrdemImpressao", vc.OrdemImpressao)
+
+				for _, vv := range vc.VotosVotaveis {
+					tipoVoto := fmt.Sprint(vv.TipoVoto)
+					t, err := TipoVotoFromEnumerated(vv.TipoVoto)
+					if err == nil {
+						tipoVoto = t.String()
+					}
+
+					votavel := cargo + "." + tipoVoto
+					if vv.IdentificacaoVotavel != (IdentificacaoVotavel{}) {
+						votavel = fmt.Sprintf("%s %d", votavel, vv.IdentificacaoVotavel.Codigo)
+					}
+
+					add(votavel, vv.QuantidadeVotos)
+					if !ignoreAssinaturas {
+						add(votavel+".Assinatura", fmt.Sprintf("%x", vv.Assinatura))
+					}
+				}
+			}
+		}
+	}
+
+	return campos, valores
+}
+
+func flattenInspect(campo string, v any, add func(string, any)) {
+	switch v := v.(type) {
+	case InspectStruct:
+		for _, f := range v {
+			flattenInspect(campo+"."+f.Name, f.Value, add)
+		}
+	case []any:
+		for i, item := range v {
+			flattenInspect(fmt.Sprintf("%s[%d]", campo, i), item, add)
+		}
+	default:
+		add(campo, v)
+	}
+}
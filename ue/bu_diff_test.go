@@ -0,0 +1,46 @@
+package ue
+
+import (
+	"testing"
+)
+
+func TestDiffBu(t *testing.T) {
+	a, err := BuEntry{"test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := DiffBu(a, a, false); len(d) != 0 {
+		t.Error("expected no differences", d)
+	}
+
+	b, err := BuEntry{"test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.DataHoraEmissao = "20221002T180000"
+	b.Urna.TipoArquivo = 0x02
+	vv := &b.ResultadosVotacaoPorEleicao[0].ResultadosVotacao[0].TotaisVotosCargo[0].VotosVotaveis[0]
+	vv.QuantidadeVotos++
+	vv.Assinatura = []byte{0}
+
+	expected := map[string]DiferencaBu{
+		"DataHoraEmissao":                           {"DataHoraEmissao", "20221002T170353", "20221002T180000"},
+		"Urna.TipoArquivo":                          {"Urna.TipoArquivo", "VotacaoUE", "VotacaoRED"},
+		"Eleicao 546.Deputado Federal.Nominal 1012": {"Eleicao 546.Deputado Federal.Nominal 1012", "3", "4"},
+	}
+
+	d := DiffBu(a, b, true)
+	if len(d) != len(expected) {
+		t.Error("wrong differences", d)
+	}
+	for _, diferenca := range d {
+		if expected[diferenca.Campo] != diferenca {
+			t.Error("unexpected difference", diferenca)
+		}
+	}
+
+	if d := DiffBu(a, b, false); len(d) != len(expected)+1 {
+		t.Error("expected signature difference", d)
+	}
+}
@@ -1,8 +1,12 @@
 package ue
 
 import (
+	"os"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/google/certificate-transparency-go/asn1"
 )
 
 func TestZip(t *testing.T) {
@@ -15,7 +19,7 @@ func TestZip(t *testing.T) {
 
 		results := ValidateVotosBu(bu)
 		for _, r := range results {
-			if !r.Ok {
+			if r.Ok != Ok {
 				t.Error(r.Msg())
 			}
 		}
@@ -50,7 +54,7 @@ func TestBu(t *testing.T) {
 
 	result := ValidateVotosBu(bu)
 	for _, r := range result {
-		if !r.Ok {
+		if r.Ok != Ok {
 			t.Error(r.Msg())
 		}
 	}
@@ -98,3 +102,131 @@ func TestBu(t *testing.T) {
 		t.Error("wrong secao", i.(IdentificacaoSecaoEleitoral).Secao)
 	}
 }
+
+func TestValidateVotosBuSecao(t *testing.T) {
+	data, err := os.ReadFile("test-data/urna.bu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bu, err := readBuFromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := ValidateVotosBu(bu)
+	if len(results) == 0 {
+		t.Fatal("expected results")
+	}
+
+	// Zona 7, local 1023, seção 55.
+	for _, r := range results {
+		if r.Zona != "7" || r.Secao != "55" {
+			t.Error("wrong section", r.Zona, r.Secao)
+			break
+		}
+	}
+}
+
+func TestListVotosBu(t *testing.T) {
+	b, err := BuEntry{"test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	votos := ListVotosBu(b)
+
+	var total int
+	cargos := make(map[string]bool)
+	for _, v := range votos {
+		cargos[v.Cargo] = true
+		if v.Cargo == Presidente.String() {
+			total += v.Quantidade
+		}
+	}
+
+	counted := CountVotosBu(b, []CargoConstitucional{Presidente})[Presidente]
+	var countedTotal int
+	for _, n := range counted {
+		countedTotal += n
+	}
+
+	if total != countedTotal || total == 0 {
+		t.Error("wrong total for Presidente", total, countedTotal)
+	}
+	if len(cargos) < 2 {
+		t.Error("expected several cargos", cargos)
+	}
+	if votos[0].Cargo != DeputadoFederal.String() || votos[0].TipoVoto != Nominal || votos[0].Codigo != 1012 || votos[0].Quantidade != 3 {
+		t.Error("wrong first votável", votos[0])
+	}
+}
+
+func TestDataHoraJETime(t *testing.T) {
+	d, err := DataHoraJE("20221030T150436").TimeIn(time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Year() != 2022 || d.Month() != 10 || d.Day() != 30 || d.Hour() != 15 || d.Minute() != 4 || d.Second() != 36 {
+		t.Error("unexpected time", d)
+	}
+
+	_, err = DataHoraJE("").TimeIn(time.UTC)
+	if err == nil {
+		t.Error("expected error for empty date")
+	}
+}
+
+func TestReadIdentificacao(t *testing.T) {
+	data, err := os.ReadFile("test-data/urna.bu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var eeg EntidadeEnvelopeGenerico
+	_, err = asn1.Unmarshal(data, &eeg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := eeg.ReadIdentificacao()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch i := i.(type) {
+	case IdentificacaoSecaoEleitoral:
+		if i.Secao != 55 || i.GetMunicipioZona().Zona != 7 {
+			t.Error("unexpected identificacao", i)
+		}
+	default:
+		t.Errorf("expected identificacao secao eleitoral, got %T", i)
+	}
+
+	mesa, err := asn1.Marshal(IdentificacaoMesaJustificativa{MunicipioZona{1007, 9}, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seq asn1.RawValue
+	_, err = asn1.Unmarshal(mesa, &seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr := CorrespondenciaResultado{Identificacao: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: seq.Bytes}}
+	i, err = cr.ReadIdentificacao()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := i.(IdentificacaoMesaJustificativa)
+	if !ok || m.Mesa != 3 || m.Urna != 4 || m.GetMunicipioZona().Municipio != 1007 {
+		t.Error("unexpected identificacao", i)
+	}
+
+	id, err := eeg.Cabecalho.ReadIdEleitoral()
+	if p, ok := id.(IDPleito); err != nil || !ok || p != 406 {
+		t.Error("unexpected id eleitoral", id, err)
+	}
+}
@@ -0,0 +1,103 @@
+package ue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The 4-byte serial as an integer.
+func (n NumeroSerieFlash) Uint32() (uint32, error) {
+	if len(n) != 4 {
+		return 0, fmt.Errorf("numero de serie flash has %d bytes, expected 4", len(n))
+	}
+
+	return binary.BigEndian.Uint32(n), nil
+}
+
+// Urna and flash cards of a section, as recorded in its BU. A flash de carga
+// loads many urnas, so sharing it is not an anomaly; it is only counted.
+type CargaInfo struct {
+	Municipio               string    `json:"municipio"`
+	Zona                    string    `json:"zona"`
+	Secao                   string    `json:"secao"`
+	TipoUrna                string    `json:"tipoUrna"`
+	NumeroInternoUrna       int       `json:"numeroInternoUrna"`
+	NumeroSerieFC           uint32    `json:"numeroSerieFC"` // Flash de carga.
+	NumeroSerieFV           uint32    `json:"numeroSerieFV"` // Flash de votação.
+	DataHoraCarga           time.Time `json:"dataHoraCarga"` // Local time of the UF, in UTC.
+	CodigoCarga             string    `json:"codigoCarga"`
+	SecoesFlashCarga        int       `json:"secoesFlashCarga"`        // Sections loaded by the same flash de carga.
+	UrnaReutilizada         bool      `json:"urnaReutilizada"`         // Same urna found in another section.
+	FlashVotacaoReutilizada bool      `json:"flashVotacaoReutilizada"` // Same flash de votação found in another section.
+	Erro                    string    `json:"erro,omitempty"`          // Why fields could not be decoded.
+
+	municipio CodigoMunicipio
+}
+
+func CargaFromBu(b EntidadeBoletimUrna) CargaInfo {
+	c := b.Urna.CorrespondenciaResultado.Carga
+	m := b.Municipio()
+	info := CargaInfo{
+		Municipio:         m.String(),
+		Zona:              fmt.Sprint(b.IdentificacaoSecao.MunicipioZona.Zona),
+		Secao:             fmt.Sprint(b.IdentificacaoSecao.Secao),
+		TipoUrna:          b.Urna.Tipo().String(),
+		NumeroInternoUrna: int(c.NumeroInternoUrna),
+		CodigoCarga:       c.CodigoCarga,
+		municipio:         b.IdentificacaoSecao.MunicipioZona.Municipio,
+	}
+
+	var errFC, errFV, errData error
+	info.NumeroSerieFC, errFC = c.NumeroSerieFC.Uint32()
+	info.NumeroSerieFV, errFV = b.Urna.NumeroSerieFV.Uint32()
+	info.DataHoraCarga, errData = c.DataHoraCarga.TimeIn(m.Location())
+	if errData == nil {
+		info.DataHoraCarga = info.DataHoraCarga.UTC()
+	}
+
+	err := errors.Join(errFC, errFV, errData)
+	if err != nil {
+		info.Erro = err.Error()
+	}
+
+	return info
+}
+
+// Flags entries whose urna or flash de votação also appear in a different
+// section, and counts the sections of each flash de carga.
+func FlagCargaReuse(infos []CargaInfo) {
+	owners := func(key func(CargaInfo) uint32) map[uint32]map[string]bool {
+		owners := make(map[uint32]map[string]bool)
+		for _, info := range infos {
+			k := key(info)
+			if k == 0 {
+				continue
+			}
+
+			if owners[k] == nil {
+				owners[k] = make(map[string]bool)
+			}
+			owners[k][info.owner()] = true
+		}
+
+		return owners
+	}
+
+	urnas := owners(func(i CargaInfo) uint32 { return uint32(i.NumeroInternoUrna) })
+	fcs := owners(func(i CargaInfo) uint32 { return i.NumeroSerieFC })
+	fvs := owners(func(i CargaInfo) uint32 { return i.NumeroSerieFV })
+
+	for i := range infos {
+		info := &infos[i]
+		info.UrnaReutilizada = len(urnas[uint32(info.NumeroInternoUrna)]) > 1
+		info.FlashVotacaoReutilizada = len(fvs[info.NumeroSerieFV]) > 1
+		info.SecoesFlashCarga = len(fcs[info.NumeroSerieFC])
+	}
+}
+
+// Section of the entry, by municipio code as names are not unique.
+func (info CargaInfo) owner() string {
+	return fmt.Sprintf("%d/%s/%s", info.municipio, info.Zona, info.Secao)
+}
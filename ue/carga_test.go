@@ -0,0 +1,59 @@
+package ue
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCargaFromBu(t *testing.T) {
+	b, err := BuEntry{"test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := CargaFromBu(b)
+	if c.Erro != "" {
+		t.Fatal(c.Erro)
+	}
+
+	if c.NumeroInternoUrna != 1842411 || c.NumeroSerieFC != 0x0736f3b5 || c.NumeroSerieFV != 0x28a659b6 {
+		t.Error("unexpected carga", c)
+	}
+	// ACEGUA (RS) is at -03:00.
+	if !c.DataHoraCarga.Equal(time.Date(2022, 9, 19, 14, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected data hora carga", c.DataHoraCarga)
+	}
+
+	if _, err := NumeroSerieFlash([]byte{1, 2}).Uint32(); err == nil {
+		t.Error("expected error for short serial")
+	}
+
+	b.Urna.CorrespondenciaResultado.Carga.NumeroSerieFC = nil
+	b.Urna.NumeroSerieFV = nil
+	c = CargaFromBu(b)
+	if strings.Count(c.Erro, "numero de serie flash") != 2 {
+		t.Error("expected both serial errors, got", c.Erro)
+	}
+}
+
+func TestFlagCargaReuse(t *testing.T) {
+	infos := []CargaInfo{
+		{Municipio: "A", Zona: "1", Secao: "1", NumeroInternoUrna: 10, NumeroSerieFC: 1, NumeroSerieFV: 2, municipio: 1},
+		{Municipio: "A", Zona: "1", Secao: "1", NumeroInternoUrna: 10, NumeroSerieFC: 1, NumeroSerieFV: 2, municipio: 1},
+		{Municipio: "A", Zona: "1", Secao: "2", NumeroInternoUrna: 11, NumeroSerieFC: 1, NumeroSerieFV: 3, municipio: 1},
+		// Same name, another municipio.
+		{Municipio: "A", Zona: "1", Secao: "2", NumeroInternoUrna: 12, NumeroSerieFC: 4, NumeroSerieFV: 3, municipio: 2},
+	}
+	FlagCargaReuse(infos)
+
+	if infos[0].UrnaReutilizada || infos[0].FlashVotacaoReutilizada {
+		t.Error("same section is not reuse", infos[0])
+	}
+	if infos[0].SecoesFlashCarga != 2 || infos[2].SecoesFlashCarga != 2 || infos[3].SecoesFlashCarga != 1 {
+		t.Error("expected sections per flash de carga", infos)
+	}
+	if !infos[2].FlashVotacaoReutilizada || !infos[3].FlashVotacaoReutilizada {
+		t.Error("expected flash de votação reuse across municipios", infos)
+	}
+}
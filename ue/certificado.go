@@ -0,0 +1,140 @@
+package ue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Inventory entry for the certificate and key descriptor of one signature (HW or SW) of a urna.
+type CertificadoInfo struct {
+	Filename         string `json:"filename"`
+	Municipio        string `json:"municipio"`
+	Zona             string `json:"zona"`
+	Secao            string `json:"secao"`
+	ModeloUrna       string `json:"modeloUrna"`
+	Assinatura       string `json:"assinatura"` // HW or SW.
+	NomeUsuario      string `json:"nomeUsuario"`
+	SerialUsuario    int    `json:"serialUsuario"`
+	ConjuntoChave    string `json:"conjuntoChave"`
+	Subject          string `json:"subject,omitempty"`
+	Serial           string `json:"serial,omitempty"`
+	Issuer           string `json:"issuer,omitempty"`
+	NotBefore        string `json:"notBefore,omitempty"`
+	NotAfter         string `json:"notAfter,omitempty"`
+	FingerprintChave string `json:"fingerprintChave,omitempty"` // SHA-256 of the DER SubjectPublicKeyInfo.
+	FingerprintCert  string `json:"fingerprintCert,omitempty"`  // SHA-256 of the DER certificate.
+	ChaveReutilizada bool   `json:"chaveReutilizada"`           // Same public key found in another section or modelo de urna.
+	CertReutilizado  bool   `json:"certReutilizado"`            // Same certificate found in another section or modelo de urna.
+	ErroCertificado  string `json:"erroCertificado,omitempty"`  // Why the certificate could not be parsed.
+
+	municipio CodigoMunicipio // 0 if the filename does not name a section.
+}
+
+func InventoryVscmr(path string) []CertificadoInfo {
+	sig, err := ReadAssinatura(path)
+	if err != nil {
+		log.Printf("error processing %s", path)
+		return []CertificadoInfo{}
+	}
+
+	return inventory(path, sig)
+}
+
+func InventoryZip(path string) []CertificadoInfo {
+	var infos []CertificadoInfo
+
+	ProcessZip(path, func(sig EntidadeAssinaturaResultado, ctx ZipProcessCtx) {
+		infos = append(infos, inventory(ctx.Filename, sig)...)
+	})
+
+	return infos
+}
+
+func inventory(path string, sig EntidadeAssinaturaResultado) []CertificadoInfo {
+	modelo := ModeloUrna(sig.ModeloUrna).String()
+
+	return []CertificadoInfo{
+		newCertificadoInfo(path, modelo, "HW", sig.AssinaturaHW),
+		newCertificadoInfo(path, modelo, "SW", sig.AssinaturaSW),
+	}
+}
+
+func newCertificadoInfo(path string, modelo string, assinatura string, sig EntidadeAssinatura) CertificadoInfo {
+	info := CertificadoInfo{
+		Filename:      path,
+		ModeloUrna:    modelo,
+		Assinatura:    assinatura,
+		NomeUsuario:   sig.AutoAssinado.Usuario.NomeUsuario,
+		SerialUsuario: sig.AutoAssinado.Usuario.Serial,
+		ConjuntoChave: sig.ConjuntoChave,
+	}
+	info.Municipio, info.Zona, info.Secao = sectionFields(path)
+	if s, err := ParseSectionFilename(path); err == nil {
+		info.municipio = s.Municipio
+	}
+
+	if len(sig.CertificadoDigital) == 0 {
+		return info
+	}
+
+	cert, err := sig.ParseCertificate()
+	if err != nil {
+		info.ErroCertificado = err.Error()
+		return info
+	}
+
+	fingerprintChave := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	fingerprintCert := sha256.Sum256(cert.Raw)
+
+	info.Subject = cert.Subject.String()
+	info.Serial = cert.SerialNumber.String()
+	info.Issuer = cert.Issuer.String()
+	info.NotBefore = cert.NotBefore.Format(time.RFC3339)
+	info.NotAfter = cert.NotAfter.Format(time.RFC3339)
+	info.FingerprintChave = hex.EncodeToString(fingerprintChave[:])
+	info.FingerprintCert = hex.EncodeToString(fingerprintCert[:])
+
+	return info
+}
+
+// Flags entries whose public key or certificate also appears in a different
+// section or in a different modelo de urna.
+func FlagReuse(infos []CertificadoInfo) {
+	flag := func(fingerprint func(CertificadoInfo) string, set func(*CertificadoInfo)) {
+		owners := make(map[string]map[string]bool)
+		for _, info := range infos {
+			fp := fingerprint(info)
+			if fp == "" {
+				continue
+			}
+
+			if owners[fp] == nil {
+				owners[fp] = make(map[string]bool)
+			}
+			owners[fp][info.owner()] = true
+		}
+
+		for i := range infos {
+			fp := fingerprint(infos[i])
+			if fp != "" && len(owners[fp]) > 1 {
+				set(&infos[i])
+			}
+		}
+	}
+
+	flag(func(i CertificadoInfo) string { return i.FingerprintChave }, func(i *CertificadoInfo) { i.ChaveReutilizada = true })
+	flag(func(i CertificadoInfo) string { return i.FingerprintCert }, func(i *CertificadoInfo) { i.CertReutilizado = true })
+}
+
+// Section and modelo of the entry, by municipio code as names are not unique,
+// or its file when the section is unknown.
+func (info CertificadoInfo) owner() string {
+	if info.municipio == 0 {
+		return fmt.Sprintf("%s/%s", info.Filename, info.ModeloUrna)
+	}
+
+	return fmt.Sprintf("%d/%s/%s/%s", info.municipio, info.Zona, info.Secao, info.ModeloUrna)
+}
@@ -0,0 +1,53 @@
+package ue
+
+import (
+	"testing"
+)
+
+func TestInventory(t *testing.T) {
+	infos := InventoryZip("test-data/o00407-0100700090001.zip")
+	if len(infos) != 2 {
+		t.Fatal("expected HW and SW entries", len(infos))
+	}
+
+	hw := infos[0]
+	if hw.Subject != "CN=ueao01663883,O=TSE,ST=DF,C=BR" {
+		t.Error("wrong subject", hw.Subject)
+	}
+	if hw.NomeUsuario != "ueao01663883" || hw.ModeloUrna != "UE2013" {
+		t.Error("wrong descriptor", hw.NomeUsuario, hw.ModeloUrna)
+	}
+	if hw.FingerprintChave == "" {
+		t.Error("missing key fingerprint")
+	}
+
+	FlagReuse(infos)
+	if infos[0].ChaveReutilizada {
+		t.Error("key is not reused within a single section")
+	}
+
+	other := infos[0]
+	other.Secao = "2"
+	infos = append(infos, other)
+
+	FlagReuse(infos)
+	if !infos[0].ChaveReutilizada || !infos[0].CertReutilizado {
+		t.Error("expected key reuse across sections")
+	}
+	if infos[1].ChaveReutilizada {
+		t.Error("SW entry without certificate flagged as reused")
+	}
+
+	// Files not named after a section are told apart by their name.
+	a, b := infos[0], infos[0]
+	a.Filename, a.municipio, a.Municipio, a.Zona, a.Secao = "a.vscmr", 0, "", "", ""
+	b.Filename, b.municipio, b.Municipio, b.Zona, b.Secao = "b.vscmr", 0, "", "", ""
+	a.ChaveReutilizada, a.CertReutilizado = false, false
+	b.ChaveReutilizada, b.CertReutilizado = false, false
+	unknown := []CertificadoInfo{a, b}
+
+	FlagReuse(unknown)
+	if !unknown[0].ChaveReutilizada || !unknown[1].CertReutilizado {
+		t.Error("expected key reuse across files of unknown sections")
+	}
+}
@@ -0,0 +1,226 @@
+package ue
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ErrUnregisteredKey = errors.New("bu key is not registered")
+	ErrKeyMismatch     = errors.New("bu key differs from the registered one")
+	ErrSharedKey       = errors.New("bu key is shared with another section")
+)
+
+// Expected Ed25519 public keys (`ChaveAssinaturaVotosVotavel`) per section and per carga.
+type ChaveRegistry struct {
+	secoes map[string][]byte
+	cargas map[string][]byte
+}
+
+func NewChaveRegistry() *ChaveRegistry {
+	return &ChaveRegistry{
+		secoes: make(map[string][]byte),
+		cargas: make(map[string][]byte),
+	}
+}
+
+// Loads keys from a CSV file whose rows are either
+// `<municipio>,<zona>,<secao>,<chave>` or `<codigo carga>,<chave>`, keys
+// hex-encoded; a header row is skipped.
+func LoadChaveRegistry(path string) (*ChaveRegistry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := NewChaveRegistry()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) != 2 && len(record) != 4 {
+			return nil, fmt.Errorf("invalid record in %s: %v", path, record)
+		}
+
+		var id IdentificacaoSecaoEleitoral
+		if len(record) == 4 {
+			_, err := fmt.Sscanf(
+				strings.Join(record[:3], " "), "%d %d %d",
+				&id.MunicipioZona.Municipio, &id.MunicipioZona.Zona, &id.Secao)
+			if err != nil && first {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid section in %s: %w", path, err)
+			}
+		}
+
+		chave, err := hex.DecodeString(strings.TrimSpace(record[len(record)-1]))
+		if err != nil && first {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key in %s: %w", path, err)
+		}
+
+		if len(record) == 2 {
+			r.RegisterCarga(strings.TrimSpace(record[0]), chave)
+		} else {
+			r.RegisterSecao(id, chave)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *ChaveRegistry) RegisterSecao(id IdentificacaoSecaoEleitoral, chave []byte) {
+	r.secoes[secaoKey(id)] = chave
+}
+
+func (r *ChaveRegistry) RegisterCarga(codigoCarga string, chave []byte) {
+	r.cargas[codigoCarga] = chave
+}
+
+// Registers the keys of the BUs inside `path` whose file hash and signature verify
+// against the section's `.vscmr`, i.e. keys vouched for by the urna certificate.
+// The certificate comes from the same zip, so `path` must be obtained
+// independently of the files verified against the registry.
+func (r *ChaveRegistry) DeriveZip(path string) {
+	failed := make(map[string]bool)
+	signed := make(map[string]bool)
+	for _, result := range VerifyAssinaturaZip(path) {
+		if !strings.HasSuffix(result.Filename, EntidadeEnvelopeGenerico{}.Extension()) {
+			continue
+		}
+
+		if result.Ok == Nok && (result.Type == Hash || result.Type == Signature) {
+			failed[result.Filename] = true
+		}
+		if result.Ok == Ok && result.Type == Signature {
+			signed[result.Filename] = true
+		}
+	}
+
+	ProcessZipRaw(path, func(f *zip.File) bool {
+		if failed[f.Name] || !signed[f.Name] {
+			return false
+		}
+
+		data, err := ReadZipFile(f)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+
+		b, err := readBuFromBytes(data)
+		if err != nil {
+			log.Println(err)
+			return false
+		}
+
+		r.RegisterSecao(b.IdentificacaoSecao, b.ChaveAssinaturaVotosVotavel)
+		r.RegisterCarga(b.Urna.CorrespondenciaResultado.Carga.CodigoCarga, b.ChaveAssinaturaVotosVotavel)
+
+		return false
+	})
+}
+
+// Checks the BU key against the one registered for its section or, failing that, its carga.
+func (r *ChaveRegistry) VerifyBu(b EntidadeBoletimUrna) VerificationResult {
+	expected, ok := r.secoes[secaoKey(b.IdentificacaoSecao)]
+	if !ok {
+		expected, ok = r.cargas[b.Urna.CorrespondenciaResultado.Carga.CodigoCarga]
+	}
+
+	if !ok {
+		return newKeyResult(b, Unverifiable, nil, ErrUnregisteredKey.Error())
+	}
+
+	if !bytes.Equal(expected, b.ChaveAssinaturaVotosVotavel) {
+		return newKeyResult(b, Nok, ErrKeyMismatch, "")
+	}
+
+	return newKeyResult(b, Ok, nil, "")
+}
+
+// Tracks which sections use each BU key, to detect keys shared between sections.
+type ChaveUsage struct {
+	secoes map[string]map[string]IdentificacaoSecaoEleitoral
+}
+
+func NewChaveUsage() *ChaveUsage {
+	return &ChaveUsage{secoes: make(map[string]map[string]IdentificacaoSecaoEleitoral)}
+}
+
+func (u *ChaveUsage) Track(b EntidadeBoletimUrna) {
+	chave := string(b.ChaveAssinaturaVotosVotavel)
+	if u.secoes[chave] == nil {
+		u.secoes[chave] = make(map[string]IdentificacaoSecaoEleitoral)
+	}
+
+	u.secoes[chave][secaoKey(b.IdentificacaoSecao)] = b.IdentificacaoSecao
+}
+
+// One result per tracked section; Nok when its key is used by any other section.
+func (u *ChaveUsage) Results() []VerificationResult {
+	var results []VerificationResult
+
+	chaves := maps.Keys(u.secoes)
+	slices.Sort(chaves)
+
+	for _, chave := range chaves {
+		secoes := u.secoes[chave]
+		ids := maps.Keys(secoes)
+		slices.Sort(ids)
+
+		for _, k := range ids {
+			id := secoes[k]
+			b := EntidadeBoletimUrna{IdentificacaoSecao: id, ChaveAssinaturaVotosVotavel: []byte(chave)}
+			if len(secoes) > 1 {
+				results = append(results, newKeyResult(b, Nok, ErrSharedKey, ""))
+			} else {
+				results = append(results, newKeyResult(b, Ok, nil, ""))
+			}
+		}
+	}
+
+	return results
+}
+
+func secaoKey(id IdentificacaoSecaoEleitoral) string {
+	return fmt.Sprintf("%d/%d/%d", id.MunicipioZona.Municipio, id.MunicipioZona.Zona, id.Secao)
+}
+
+func newKeyResult(b EntidadeBoletimUrna, status VerificationResultStatus, err error, reason string) VerificationResult {
+	return VerificationResult{
+		Type:      Key,
+		Ok:        status,
+		Err:       err,
+		Reason:    reason,
+		Municipio: b.Municipio().String(),
+		Zona:      fmt.Sprint(b.IdentificacaoSecao.MunicipioZona.Zona),
+		Secao:     fmt.Sprint(b.IdentificacaoSecao.Secao),
+		Payload:   []byte(hex.EncodeToString(b.ChaveAssinaturaVotosVotavel)),
+	}
+}
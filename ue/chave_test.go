@@ -0,0 +1,82 @@
+package ue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChaveRegistry(t *testing.T) {
+	var bu EntidadeBoletimUrna
+	ProcessZip("test-data/o00407-0100700090001.zip", func(eeg EntidadeEnvelopeGenerico) error {
+		var err error
+		bu, err = eeg.ReadBu()
+		return err
+	})
+
+	registry := NewChaveRegistry()
+	if r := registry.VerifyBu(bu); r.Ok != Unverifiable {
+		t.Error("expected unregistered key", r.Msg())
+	}
+
+	registry.DeriveZip("test-data/o00407-0100700090001.zip")
+	if r := registry.VerifyBu(bu); r.Ok != Ok {
+		t.Error("expected key derived from vscmr", r.Msg(), r.Err)
+	}
+
+	forged := bu
+	forged.ChaveAssinaturaVotosVotavel = make([]byte, len(bu.ChaveAssinaturaVotosVotavel))
+	if r := registry.VerifyBu(forged); !errors.Is(r.Err, ErrKeyMismatch) {
+		t.Error("expected key mismatch", r.Msg())
+	}
+
+	path := filepath.Join(t.TempDir(), "keys.csv")
+	err := os.WriteFile(path, []byte("carga,chave\n"+bu.Urna.CorrespondenciaResultado.Carga.CodigoCarga+",00\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err = LoadChaveRegistry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := registry.VerifyBu(bu); !errors.Is(r.Err, ErrKeyMismatch) {
+		t.Error("expected key mismatch against file registry", r.Msg())
+	}
+
+	err = os.WriteFile(path, []byte("1007,9,1,00\n1007,9,2,chave\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadChaveRegistry(path)
+	if err == nil {
+		t.Error("expected invalid key after the first row")
+	}
+}
+
+func TestChaveUsage(t *testing.T) {
+	a := EntidadeBoletimUrna{ChaveAssinaturaVotosVotavel: []byte{1}}
+	a.IdentificacaoSecao.Secao = 1
+	b := EntidadeBoletimUrna{ChaveAssinaturaVotosVotavel: []byte{1}}
+	b.IdentificacaoSecao.Secao = 2
+	c := EntidadeBoletimUrna{ChaveAssinaturaVotosVotavel: []byte{2}}
+	c.IdentificacaoSecao.Secao = 3
+
+	usage := NewChaveUsage()
+	for _, bu := range []EntidadeBoletimUrna{a, b, c, a} {
+		usage.Track(bu)
+	}
+
+	var shared int
+	for _, r := range usage.Results() {
+		if errors.Is(r.Err, ErrSharedKey) {
+			shared++
+		}
+	}
+
+	if shared != 2 {
+		t.Error("wrong number of sections sharing a key", shared)
+	}
+}
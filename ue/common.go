@@ -2,13 +2,15 @@ package ue
 
 import (
 	"archive/zip"
-	"bytes"
+	"errors"
+	"fmt"
 	"github.com/google/certificate-transparency-go/asn1"
 	"io"
 	"log"
 	"os"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 func FillSlice(bytes []byte, form any) error {
@@ -59,44 +61,72 @@ func FillSequence(bytes []byte, form any) error {
 	return nil
 }
 
-var zipCache map[string]*zip.ReadCloser = make(map[string]*zip.ReadCloser)
+// Limits of a zip, so that a crafted one cannot exhaust memory.
+var (
+	MaxZipEntries   = 4096
+	MaxZipEntrySize = int64(64 << 20)
+)
+
+var ErrZipTooLarge = errors.New("zip exceeds the size limits")
+
+type cachedZip struct {
+	r    *zip.ReadCloser
+	refs int
+}
+
+// Zips in use, shared by concurrent callers; see openZipReader.
+var (
+	zipCacheMu sync.Mutex
+	zipCache   = make(map[string]*cachedZip)
+)
 
 type ZipProcessCtx struct {
 	ZipFilename string // name of the `*.zip` file
 	Filename    string // name of the file inside the `*.zip` file
 }
 
-func ProcessAllZipRaw(dir string, process func(*zip.File) bool) {
+func ProcessAllZipRaw(dir string, process func(*zip.File) bool) error {
 	dirEntries, err := os.ReadDir(dir)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	for _, e := range dirEntries {
 		if !e.IsDir() && strings.HasSuffix(e.Name(), ".zip") {
-			ProcessZipRaw((strings.Join([]string{dir, e.Name()}, "/")), process)
+			err := ProcessZipRaw((strings.Join([]string{dir, e.Name()}, "/")), process)
+			if err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
 }
 
-func ProcessAllZip(dir string, process any) {
+func ProcessAllZip(dir string, process any) error {
 	dirEntries, err := os.ReadDir(dir)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	for _, e := range dirEntries {
 		if !e.IsDir() && strings.HasSuffix(e.Name(), ".zip") {
-			ProcessZip((strings.Join([]string{dir, e.Name()}, "/")), process)
+			err := ProcessZip((strings.Join([]string{dir, e.Name()}, "/")), process)
+			if err != nil {
+				return err
+			}
 		}
 	}
+
+	return nil
 }
 
-func ProcessZipRaw(path string, process func(*zip.File) bool) {
-	r, err := openZipReader(path)
+func ProcessZipRaw(path string, process func(*zip.File) bool) error {
+	r, release, err := openZipReader(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	defer release()
 
 	for _, f := range r.File {
 		done := process(f)
@@ -104,13 +134,18 @@ func ProcessZipRaw(path string, process func(*zip.File) bool) {
 			break
 		}
 	}
+
+	return nil
 }
 
-func ProcessZip(path string, process any) {
-	r, err := openZipReader(path)
+// Calls `process` for every file inside the zip whose extension matches the entity it takes.
+// Files that cannot be decoded are skipped; the first such error is returned.
+func ProcessZip(path string, process any) error {
+	r, release, err := openZipReader(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	defer release()
 
 	entityType := reflect.TypeOf(process).In(0)
 	entity := reflect.New(entityType)
@@ -121,28 +156,25 @@ func ProcessZip(path string, process any) {
 	}
 	extension := extensionMethod.Call([]reflect.Value{})[0]
 
+	var decodeErr error
 	for _, f := range r.File {
 		if strings.HasSuffix(f.Name, extension.String()) {
-			rc, err := f.Open()
+			data, err := ReadZipFile(f)
 			if err != nil {
-				log.Println("could not open file inside zip:", err)
-				return
-			}
-
-			var buf bytes.Buffer
-			io.Copy(&buf, rc)
-
-			if err != nil {
-				log.Println(err)
-				return
+				log.Println("could not read file inside zip:", err)
+				return err
 			}
 
 			functionType := reflect.TypeOf(process)
 			entityType := functionType.In(0)
 			entity := reflect.New(entityType)
-			_, err = asn1.Unmarshal(buf.Bytes(), entity.Interface())
+			_, err = asn1.Unmarshal(data, entity.Interface())
 			if err != nil {
 				log.Println(err)
+				if decodeErr == nil {
+					decodeErr = fmt.Errorf("%s: %w", f.Name, err)
+				}
+				continue
 			}
 
 			if functionType.NumIn() > 1 {
@@ -158,44 +190,76 @@ func ProcessZip(path string, process any) {
 			} else {
 				reflect.ValueOf(process).Call([]reflect.Value{entity.Elem()})
 			}
+		}
+	}
 
-			if err != nil {
-				log.Println(err)
-			}
+	return decodeErr
+}
 
-			rc.Close()
-		}
+// Reads a file inside a zip, up to MaxZipEntrySize bytes.
+func ReadZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(MaxZipEntrySize) {
+		return nil, fmt.Errorf("%s: %w", f.Name, ErrZipTooLarge)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// The header may lie about the size.
+	data, err := io.ReadAll(io.LimitReader(rc, MaxZipEntrySize+1))
+	if err != nil {
+		return nil, err
 	}
+	if int64(len(data)) > MaxZipEntrySize {
+		return nil, fmt.Errorf("%s: %w", f.Name, ErrZipTooLarge)
+	}
+
+	return data, nil
 }
 
-func openZipReader(path string) (*zip.ReadCloser, error) {
-	r, ok := zipCache[path]
+// Opens a zip, or shares the reader of a concurrent caller; `release` must be
+// called when done. The last release closes the reader and forgets it, so a
+// zip replaced or deleted afterwards (e.g. the uploads of `urna serve`) is
+// never read through a stale reader nor kept open.
+func openZipReader(path string) (*zip.Reader, func(), error) {
+	zipCacheMu.Lock()
+	defer zipCacheMu.Unlock()
+
+	c, ok := zipCache[path]
 	if !ok {
-		var err error
-		r, err = zip.OpenReader(path)
+		r, err := zip.OpenReader(path)
 		if err != nil {
 			log.Println("could not open:", err)
-			return nil, err
+			return nil, nil, err
+		}
+		if len(r.File) > MaxZipEntries {
+			r.Close()
+			return nil, nil, fmt.Errorf("%s: %d entries: %w", path, len(r.File), ErrZipTooLarge)
 		}
 
-		zipCache[path] = r
+		c = &cachedZip{r: r}
+		zipCache[path] = c
+	}
+	c.refs++
 
-		if len(zipCache) > 10 {
-			for f, r := range zipCache {
-				if f == path {
-					continue
-				}
+	release := func() {
+		zipCacheMu.Lock()
+		defer zipCacheMu.Unlock()
 
-				err := r.Close()
-				if err != nil {
-					log.Println("could not close:", err)
-				}
-				delete(zipCache, f)
+		c.refs--
+		if c.refs > 0 {
+			return
+		}
 
-				break
-			}
+		delete(zipCache, path)
+		err := c.r.Close()
+		if err != nil {
+			log.Println("could not close:", err)
 		}
 	}
 
-	return r, nil
+	return &c.r.Reader, release, nil
 }
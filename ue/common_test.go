@@ -0,0 +1,127 @@
+package ue
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestZipLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := zip.NewWriter(f)
+	for i := 0; i < 3; i++ {
+		fw, err := w.Create(fmt.Sprintf("%d.bu", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write(make([]byte, 1024))
+	}
+	w.Close()
+	f.Close()
+
+	defer func(entries int, size int64) {
+		MaxZipEntries, MaxZipEntrySize = entries, size
+	}(MaxZipEntries, MaxZipEntrySize)
+
+	MaxZipEntrySize = 1023
+	var readErr error
+	ProcessZipRaw(path, func(f *zip.File) bool {
+		_, readErr = ReadZipFile(f)
+		return true
+	})
+	if !errors.Is(readErr, ErrZipTooLarge) {
+		t.Error("expected entry too large, got", readErr)
+	}
+
+	MaxZipEntries = 2
+	err = ProcessZipRaw(path, func(f *zip.File) bool { return true })
+	if !errors.Is(err, ErrZipTooLarge) {
+		t.Error("expected too many entries, got", err)
+	}
+}
+
+// A zip replaced at the same path, as `urna serve` does with temporary
+// directories, must not be read through the reader of the old file.
+func TestZipReplaced(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload.zip")
+	write := func(name string) {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := zip.NewWriter(f)
+		_, err = w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		f.Close()
+	}
+	names := func() []string {
+		var n []string
+		err := ProcessZipRaw(path, func(f *zip.File) bool {
+			n = append(n, f.Name)
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	write("a.bu")
+	if n := names(); len(n) != 1 || n[0] != "a.bu" {
+		t.Fatal("wrong entries", n)
+	}
+
+	os.Remove(path)
+	write("b.bu")
+	if n := names(); len(n) != 1 || n[0] != "b.bu" {
+		t.Error("read the replaced zip", n)
+	}
+
+	zipCacheMu.Lock()
+	defer zipCacheMu.Unlock()
+	if len(zipCache) != 0 {
+		t.Error("readers kept open after release", len(zipCache))
+	}
+}
+
+// Releasing readers while others use them must not close them (run with -race).
+func TestZipCacheConcurrent(t *testing.T) {
+	data, err := os.ReadFile("test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < 24; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%d.zip", i))
+		err = os.WriteFile(path, data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var n int
+			err := ProcessZip(path, func(eeg EntidadeEnvelopeGenerico) { n++ })
+			if err != nil || n != 1 {
+				t.Error("expected one BU in", path, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
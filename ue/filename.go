@@ -1,18 +1,79 @@
 package ue
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 )
 
+var ErrNotSectionFilename = errors.New("not a section filename")
+
+// Section identification encoded in TSE filenames, e.g. `o00407-0100700090001.zip`
+// for pleito 407, município 1007, zona 9 and seção 1.
+type SectionFilename struct {
+	Pleito    IDPleito
+	Municipio CodigoMunicipio
+	Zona      NumeroZona
+	Secao     NumeroSecao
+	Extensao  string // Including the dot, e.g. `.zip`; empty if none.
+}
+
+// Parses the base name of `path`; names not in the TSE format give
+// ErrNotSectionFilename.
+func ParseSectionFilename(path string) (SectionFilename, error) {
+	name := filepath.Base(path)
+	if len(name) < 20 {
+		return SectionFilename{}, fmt.Errorf("%s: %w", name, ErrNotSectionFilename)
+	}
+
+	stem, ext := name[:20], name[20:]
+	if !isLetter(stem[0]) || stem[6] != '-' || (len(ext) > 0 && ext[0] != '.') {
+		return SectionFilename{}, fmt.Errorf("%s: %w", name, ErrNotSectionFilename)
+	}
+
+	var fields [4]int
+	for i, r := range [][2]int{{1, 6}, {7, 12}, {12, 16}, {16, 20}} {
+		n, err := parseDigits(stem[r[0]:r[1]])
+		if err != nil {
+			return SectionFilename{}, fmt.Errorf("%s: %w", name, ErrNotSectionFilename)
+		}
+		fields[i] = n
+	}
+
+	return SectionFilename{
+		Pleito:    IDPleito(fields[0]),
+		Municipio: CodigoMunicipio(fields[1]),
+		Zona:      NumeroZona(fields[2]),
+		Secao:     NumeroSecao(fields[3]),
+		Extensao:  ext,
+	}, nil
+}
+
+// Município, zona and seção as shown in results; empty when `path` is not a
+// section filename.
+func sectionFields(path string) (string, string, string) {
+	s, err := ParseSectionFilename(path)
+	if err != nil {
+		return "", "", ""
+	}
+
+	m, err := MunicipioFromIdPleito(int(s.Municipio), s.Pleito)
+	if err != nil {
+		return fmt.Sprint(s.Municipio), fmt.Sprint(s.Zona), fmt.Sprint(s.Secao)
+	}
+
+	return m.String(), fmt.Sprint(s.Zona), fmt.Sprint(s.Secao)
+}
+
+// Deprecated: use ParseSectionFilename.
 func MunicipioByFile(filename string) string {
-	id, err := strconv.Atoi(filename[7:12])
+	s, err := ParseSectionFilename(filename)
 	if err != nil {
-		fmt.Println(err)
 		return filename
 	}
 
-	m, err := MunicipioFromId(id)
+	m, err := MunicipioFromIdPleito(int(s.Municipio), s.Pleito)
 	if err != nil {
 		return filename
 	}
@@ -20,20 +81,36 @@ func MunicipioByFile(filename string) string {
 	return m.String()
 }
 
+// Deprecated: use ParseSectionFilename.
 func ZonaByFile(filename string) string {
-	id, err := strconv.Atoi(filename[12:16])
+	s, err := ParseSectionFilename(filename)
 	if err != nil {
 		return filename
 	}
 
-	return strconv.Itoa(id)
+	return strconv.Itoa(int(s.Zona))
 }
 
+// Deprecated: use ParseSectionFilename.
 func SecaoByFile(filename string) string {
-	id, err := strconv.Atoi(filename[16:20])
+	s, err := ParseSectionFilename(filename)
 	if err != nil {
 		return filename
 	}
 
-	return strconv.Itoa(id)
+	return strconv.Itoa(int(s.Secao))
+}
+
+func parseDigits(s string) (int, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, fmt.Errorf("invalid digit %q", s[i])
+		}
+	}
+
+	return strconv.Atoi(s)
+}
+
+func isLetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
 }
@@ -0,0 +1,50 @@
+package ue
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSectionFilename(t *testing.T) {
+	s, err := ParseSectionFilename("test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := SectionFilename{Pleito: 407, Municipio: 1007, Zona: 9, Secao: 1, Extensao: ".zip"}
+	if s != expected {
+		t.Error("expected", expected, "got", s)
+	}
+
+	s, err = ParseSectionFilename("o00407-0100700090001")
+	if err != nil || s.Extensao != "" {
+		t.Error("expected filename without extension, got", s, err)
+	}
+
+	for _, name := range []string{
+		"urna.bu",
+		"test-data/",
+		"o00407-01007000900x1.bu",
+		"o00407_0100700090001.bu",
+		"000407-0100700090001.bu",
+		"o00407-0100700090001-1.bu",
+		"o00407--100700090001.bu",
+	} {
+		_, err := ParseSectionFilename(name)
+		if !errors.Is(err, ErrNotSectionFilename) {
+			t.Error("expected error for", name, "got", err)
+		}
+	}
+}
+
+func TestSectionFields(t *testing.T) {
+	m, z, s := sectionFields("/tmp/o00407-0100700090001.vscmr")
+	if m != "BUJARI (AC)" || z != "9" || s != "1" {
+		t.Error("wrong section", m, z, s)
+	}
+
+	m, z, s = sectionFields("test-data/urna.vscmr")
+	if m != "" || z != "" || s != "" {
+		t.Error("expected empty section, got", m, z, s)
+	}
+}
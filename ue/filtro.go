@@ -0,0 +1,172 @@
+package ue
+
+import (
+	"errors"
+	"github.com/google/certificate-transparency-go/asn1"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Restricts which sections are processed; empty fields match everything.
+// Names are compared case-insensitively with the String() of the enums.
+type Filtro struct {
+	Uf          string
+	Municipio   string // Name (ignoring case and accents) or TSE code.
+	Zona        int
+	Secao       int
+	Fase        string // e.g. Oficial, Simulado, Treinamento.
+	TipoUrna    string // e.g. Secao, Contingencia.
+	TipoArquivo string // e.g. VotacaoUE, VotacaoRED.
+	Apuracao    string // e.g. Normal, TotalmenteManual.
+}
+
+func (f Filtro) IsEmpty() bool {
+	return f == Filtro{}
+}
+
+// Checks the fields encoded in a TSE section filename (município, zona and
+// seção); fields that cannot be read from the filename match, so they must
+// still be checked against the content.
+func (f Filtro) MatchFilename(path string) bool {
+	s, err := ParseSectionFilename(path)
+	if err != nil {
+		return true
+	}
+
+	if len(f.Uf) > 0 || len(f.Municipio) > 0 {
+		m, err := MunicipioFromIdPleito(int(s.Municipio), s.Pleito)
+		if err == nil && !f.matchMunicipio(m) {
+			return false
+		}
+	}
+
+	if f.Zona != 0 && f.Zona != int(s.Zona) {
+		return false
+	}
+
+	return f.Secao == 0 || f.Secao == int(s.Secao)
+}
+
+func (f Filtro) MatchBu(b EntidadeBoletimUrna) bool {
+	return f.matchSecao(b.IdentificacaoSecao, b.Municipio()) &&
+		f.matchUrna(b.Fase, b.Urna)
+}
+
+func (f Filtro) MatchRdv(rdv EntidadeResultadoRDV) bool {
+	return f.matchSecao(rdv.Rdv.Identificacao, rdv.Municipio()) &&
+		f.matchUrna(rdv.Rdv.Fase, rdv.Urna)
+}
+
+// Matches the BU of a section zip (or its RDV if there is no BU); zips
+// with neither do not match.
+func (f Filtro) MatchZip(path string) (bool, error) {
+	var match, found bool
+
+	err := ProcessZip(path, func(eeg EntidadeEnvelopeGenerico) error {
+		bu, err := eeg.ReadBu()
+		if err != nil {
+			return err
+		}
+
+		found = true
+		match = f.MatchBu(bu)
+		return nil
+	})
+	if err != nil || found {
+		return match, err
+	}
+
+	err = ProcessZip(path, func(rdv EntidadeResultadoRDV) error {
+		found = true
+		match = f.MatchRdv(rdv)
+		return nil
+	})
+
+	return match, err
+}
+
+// Matches the `*.bu` (or else the `*.rdv`) sharing the base name of a
+// `*.vscmr`, as in AuditVscmr; if there is neither, it does not match.
+func (f Filtro) MatchVscmr(path string) (bool, error) {
+	stem := strings.TrimSuffix(path, filepath.Ext(path))
+
+	bu, err := BuEntry{Path: stem + EntidadeEnvelopeGenerico{}.Extension()}.ReadBu()
+	if err == nil {
+		return f.MatchBu(bu), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	rdv, err := ReadRdv(stem + EntidadeResultadoRDV{}.Extension())
+	if err == nil {
+		return f.MatchRdv(rdv), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	return false, nil
+}
+
+func (f Filtro) matchMunicipio(m Municipio) bool {
+	if len(f.Uf) > 0 && !strings.EqualFold(f.Uf, m.Uf) {
+		return false
+	}
+
+	if len(f.Municipio) > 0 {
+		id, err := strconv.Atoi(f.Municipio)
+		if err == nil {
+			return id == m.Id
+		}
+
+		return NormalizeNome(f.Municipio) == NormalizeNome(m.Nome)
+	}
+
+	return true
+}
+
+func (f Filtro) matchSecao(id IdentificacaoSecaoEleitoral, m Municipio) bool {
+	if (len(f.Uf) > 0 || len(f.Municipio) > 0) && !f.matchMunicipio(m) {
+		return false
+	}
+
+	if f.Zona != 0 && f.Zona != int(id.MunicipioZona.Zona) {
+		return false
+	}
+
+	return f.Secao == 0 || f.Secao == int(id.Secao)
+}
+
+func (f Filtro) matchUrna(fase asn1.Enumerated, u Urna) bool {
+	if len(f.Fase) > 0 {
+		fs, _ := FaseFromEnumerated(fase)
+		if !strings.EqualFold(f.Fase, fs.String()) {
+			return false
+		}
+	}
+
+	if len(f.TipoUrna) > 0 && !strings.EqualFold(f.TipoUrna, u.Tipo().String()) {
+		return false
+	}
+
+	if len(f.TipoArquivo) > 0 && !strings.EqualFold(f.TipoArquivo, u.TipoDeArquivo().String()) {
+		return false
+	}
+
+	if len(f.Apuracao) > 0 {
+		var tipo TipoApuracao
+		a, err := u.ReadMotivoUtilizacaoSA()
+		if err == nil {
+			tipo = a.Tipo()
+		}
+
+		if !strings.EqualFold(f.Apuracao, tipo.String()) {
+			return false
+		}
+	}
+
+	return true
+}
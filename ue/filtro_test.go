@@ -0,0 +1,68 @@
+package ue
+
+import (
+	"testing"
+)
+
+func TestFiltroMatchFilename(t *testing.T) {
+	for _, tc := range []struct {
+		filtro Filtro
+		match  bool
+	}{
+		{Filtro{}, true},
+		{Filtro{Uf: "ac"}, true},
+		{Filtro{Uf: "SP"}, false},
+		{Filtro{Municipio: "Bujari"}, true},
+		{Filtro{Municipio: "bujarí"}, true},
+		{Filtro{Municipio: "1007"}, true},
+		{Filtro{Municipio: "1008"}, false},
+		{Filtro{Zona: 9, Secao: 1}, true},
+		{Filtro{Zona: 8}, false},
+		{Filtro{Secao: 2}, false},
+		// Not in the filename.
+		{Filtro{Fase: "Simulado"}, true},
+	} {
+		if tc.filtro.MatchFilename("test-data/o00407-0100700090001.zip") != tc.match {
+			t.Error("wrong match", tc.filtro, !tc.match)
+		}
+	}
+
+	if !(Filtro{Zona: 8}).MatchFilename("test-data/urna.bu") {
+		t.Error("expected match for filename without section")
+	}
+}
+
+func TestFiltroMatchZip(t *testing.T) {
+	for _, tc := range []struct {
+		filtro Filtro
+		match  bool
+	}{
+		{Filtro{Fase: "oficial", TipoUrna: "secao", TipoArquivo: "VotacaoUE", Apuracao: "Normal"}, true},
+		{Filtro{Fase: "simulado"}, false},
+		{Filtro{TipoArquivo: "VotacaoRED"}, false},
+		{Filtro{Apuracao: "TotalmenteManual"}, false},
+		{Filtro{Uf: "AC", Zona: 9, Secao: 1}, true},
+		{Filtro{Secao: 2}, false},
+	} {
+		match, err := tc.filtro.MatchZip("test-data/o00407-0100700090001.zip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match != tc.match {
+			t.Error("wrong match", tc.filtro, match)
+		}
+	}
+}
+
+func TestFiltroMatchVscmr(t *testing.T) {
+	// urna.vscmr is read with urna.bu (Oficial, seção 55).
+	match, err := Filtro{Fase: "Oficial", Secao: 55}.MatchVscmr("test-data/urna.vscmr")
+	if err != nil || !match {
+		t.Error("expected match", err)
+	}
+
+	match, err = Filtro{Fase: "Simulado"}.MatchVscmr("test-data/urna.vscmr")
+	if err != nil || match {
+		t.Error("expected no match", err)
+	}
+}
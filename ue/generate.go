@@ -0,0 +1,8 @@
+package ue
+
+// Generates types_gen.go from the ASN.1 modules (`*.asn1`) in spec/, which are
+// reconstructed from the TSE documentation until the official ones replace
+// them, with the Go consts and texts of the ENUMERATED values in
+// spec/enums.csv. Only the CHOICE interfaces with extra methods are written by
+// hand, in the *_types.go files, with the methods on the generated types.
+//go:generate go run ./internal/asn1gen -o types_gen.go -enums spec/enums.csv spec
@@ -0,0 +1,381 @@
+package ue
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/certificate-transparency-go/asn1"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownFile = errors.New("unknown file type")
+
+// Named value of an InspectStruct.
+type InspectField struct {
+	Name  string
+	Value any
+}
+
+// Decoded ASN.1 structure; fields keep the order of the specification when
+// marshalled to JSON or YAML.
+type InspectStruct []InspectField
+
+func (s InspectStruct) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		k, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+func (s InspectStruct) MarshalYAML() (any, error) {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range s {
+		var v yaml.Node
+		err := v.Encode(f.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Name}, &v)
+	}
+
+	return n, nil
+}
+
+// Enumerated fields shown by name, keyed by field or by type and field.
+var inspectEnums = map[string]func(asn1.Enumerated) fmt.Stringer{
+	"Fase":                              func(e asn1.Enumerated) fmt.Stringer { return Fase(e) },
+	"TipoUrna":                          func(e asn1.Enumerated) fmt.Stringer { return TipoUrna(e) },
+	"TipoArquivo":                       func(e asn1.Enumerated) fmt.Stringer { return TipoArquivo(e) },
+	"TipoEnvelope":                      func(e asn1.Enumerated) fmt.Stringer { return TipoEnvelope(e) },
+	"TipoCargo":                         func(e asn1.Enumerated) fmt.Stringer { return TipoCargoConsulta(e) },
+	"TipoApuracao":                      func(e asn1.Enumerated) fmt.Stringer { return TipoApuracao(e) },
+	"Tipoapuracao":                      func(e asn1.Enumerated) fmt.Stringer { return TipoApuracao(e) },
+	"ModeloUrna":                        func(e asn1.Enumerated) fmt.Stringer { return ModeloUrna(e) },
+	"TotalVotosVotavel.TipoVoto":        func(e asn1.Enumerated) fmt.Stringer { return TipoVoto(e) },
+	"Voto.TipoVoto":                     func(e asn1.Enumerated) fmt.Stringer { return TipoVotoRdv(e) },
+	"AlgoritmoHashInfo.Algoritmo":       func(e asn1.Enumerated) fmt.Stringer { return AlgoritmoHash(e) },
+	"AlgoritmoAssinaturaInfo.Algoritmo": func(e asn1.Enumerated) fmt.Stringer { return AlgoritmoAssinatura(e) },
+	"ApuracaoEletronica.MotivoApuracao": func(e asn1.Enumerated) fmt.Stringer { return MotivoApuracaoEletronica(e) },
+	"ApuracaoMistaBUAE.MotivoApuracao":  func(e asn1.Enumerated) fmt.Stringer { return MotivoApuracaoMistaComBU(e) },
+	"ApuracaoMistaMR.MotivoApuracao":    func(e asn1.Enumerated) fmt.Stringer { return MotivoApuracaoMistaComMR(e) },
+	"ApuracaoTotalmenteManualDigitacaoAE.MotivoApuracao": func(e asn1.Enumerated) fmt.Stringer { return MotivoApuracaoManual(e) },
+}
+
+// Fields decoded by a method not named after them (`Read<Field>` is used otherwise).
+var inspectReaders = map[string]string{
+	"EntidadeAssinatura.ConteudoAutoAssinado": "ReadConteudoAssinado",
+}
+
+// Detects the type of a urna file by its envelope and tags; returns its extension.
+func DetectTipoArquivo(data []byte) (string, error) {
+	var outer asn1.RawValue
+	_, err := asn1.Unmarshal(data, &outer)
+	if err != nil {
+		return "", err
+	}
+
+	children, _, err := readTLVs(outer.Bytes)
+	if err != nil || outer.Tag != asn1.TagSequence || len(children) < 2 {
+		return "", ErrUnknownFile
+	}
+
+	switch {
+	case isEnumerated(children[0]):
+		return ".vscmr", nil
+	case isEnumerated(children[1]):
+		var e EntidadeEnvelopeGenerico
+		_, err := asn1.Unmarshal(data, &e)
+		if err != nil {
+			return "", err
+		}
+
+		switch TipoEnvelope(e.TipoEnvelope) {
+		case EnvelopeBoletimUrna:
+			return ".bu", nil
+		case EnvelopeBoletimUrnaImpresso:
+			return ".imgbu", nil
+		}
+	case children[1].Class == asn1.ClassUniversal && children[1].Tag == asn1.TagSequence:
+		return ".rdv", nil
+	}
+
+	return "", ErrUnknownFile
+}
+
+// Decodes a `*.bu`, `*.rdv`, `*.vscmr` or `*.imgbu` file with enum names and
+// resolved CHOICE variants; returns the detected extension. Unknown files and
+// fields are shown as raw TLV.
+func Inspect(data []byte) (InspectStruct, string, error) {
+	ext, err := DetectTipoArquivo(data)
+	if errors.Is(err, ErrUnknownFile) {
+		var v asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &v)
+		if err != nil {
+			return nil, "", err
+		}
+
+		s := inspectRaw(v)
+		if len(rest) > 0 {
+			s = append(s, InspectField{"Resto", inspectTLVs(rest)})
+		}
+
+		return s, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var e any
+	switch ext {
+	case ".bu", ".imgbu":
+		e = &EntidadeEnvelopeGenerico{}
+	case ".rdv":
+		e = &EntidadeResultadoRDV{}
+	case ".vscmr":
+		e = &EntidadeAssinaturaResultado{}
+	}
+
+	rest, err := asn1.Unmarshal(data, e)
+	if err != nil {
+		return nil, ext, err
+	}
+
+	s := inspectStruct(reflect.ValueOf(e).Elem(), false)
+	if len(rest) > 0 {
+		s = append(s, InspectField{"Resto", inspectTLVs(rest)})
+	}
+
+	return s, ext, nil
+}
+
+func InspectFile(path string) (InspectStruct, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return Inspect(data)
+}
+
+// With `reversible`, byte fields stay hex and enums are named only if the name
+// maps back to the same value, so that the result can be decoded again.
+func inspectStruct(v reflect.Value, reversible bool) InspectStruct {
+	s := InspectStruct{}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		f := v.Field(i)
+		if strings.Contains(field.Tag.Get("asn1"), "optional") && f.IsZero() {
+			continue
+		}
+
+		s = append(s, InspectField{field.Name, inspectField(v, field, f, reversible)})
+	}
+
+	return s
+}
+
+func inspectField(parent reflect.Value, field reflect.StructField, f reflect.Value, reversible bool) any {
+	key := parent.Type().Name() + "." + field.Name
+
+	switch f.Interface().(type) {
+	case asn1.Enumerated:
+		e := f.Interface().(asn1.Enumerated)
+		enum, ok := inspectEnum(key)
+		if ok && e > 0 && e <= 0xff {
+			name := enum(e).String()
+			if v, ok := enumValue(enum, name); !reversible || ok && v == e {
+				return name
+			}
+		}
+		return int(e)
+	case asn1.RawValue, []byte:
+		if _, ok := f.Interface().([]byte); ok && reversible {
+			break
+		}
+
+		reader, ok := inspectReaders[key]
+		if !ok {
+			reader = "Read" + field.Name
+		}
+
+		m := parent.MethodByName(reader)
+		if m.IsValid() && m.Type().NumIn() == 0 && m.Type().NumOut() == 2 {
+			out := m.Call(nil)
+			if out[1].IsNil() {
+				if _, ok := f.Interface().(asn1.RawValue); ok {
+					// CHOICE: the variant is named after its type.
+					return InspectStruct{{choiceName(out[0].Elem()), inspectValue(out[0].Elem(), reversible)}}
+				}
+				return inspectValue(out[0], reversible)
+			}
+		}
+
+		if raw, ok := f.Interface().(asn1.RawValue); ok {
+			return inspectRaw(raw)
+		}
+	}
+
+	return inspectValue(f, reversible)
+}
+
+func inspectValue(v reflect.Value, reversible bool) any {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok && v.Kind() != reflect.Struct {
+		return s.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return inspectStruct(v, reversible)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return hex.EncodeToString(v.Bytes())
+		}
+
+		items := []any{}
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, inspectValue(v.Index(i), reversible))
+		}
+		return items
+	case reflect.String:
+		return inspectString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Bool:
+		return v.Bool()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// Enum of a "Type.Field" key, falling back to the field name alone.
+func inspectEnum(key string) (func(asn1.Enumerated) fmt.Stringer, bool) {
+	enum, ok := inspectEnums[key]
+	if !ok {
+		_, name, _ := strings.Cut(key, ".")
+		enum, ok = inspectEnums[name]
+	}
+
+	return enum, ok
+}
+
+// First value whose enum name is `name`.
+func enumValue(enum func(asn1.Enumerated) fmt.Stringer, name string) (asn1.Enumerated, bool) {
+	for e := asn1.Enumerated(1); e <= 0xff; e++ {
+		if enum(e).String() == name {
+			return e, true
+		}
+	}
+
+	return 0, false
+}
+
+// GeneralStrings are ISO-8859-1.
+func inspectString(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	d, err := charmap.ISO8859_1.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+
+	return d
+}
+
+func choiceName(v reflect.Value) string {
+	t := v.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+func inspectRaw(v asn1.RawValue) InspectStruct {
+	s := InspectStruct{
+		{"tag", v.Tag},
+		{"class", v.Class},
+	}
+
+	if v.IsCompound {
+		return append(s, InspectField{"children", inspectTLVs(v.Bytes)})
+	}
+
+	return append(s, InspectField{"hex", hex.EncodeToString(v.Bytes)})
+}
+
+func inspectTLVs(data []byte) []any {
+	items := []any{}
+
+	tlvs, rest, err := readTLVs(data)
+	for _, tlv := range tlvs {
+		items = append(items, inspectRaw(tlv))
+	}
+	if err != nil {
+		items = append(items, InspectStruct{{"hex", hex.EncodeToString(rest)}})
+	}
+
+	return items
+}
+
+// Splits `data` into TLVs; on error, also returns the bytes that could not be read.
+func readTLVs(data []byte) ([]asn1.RawValue, []byte, error) {
+	var tlvs []asn1.RawValue
+	rest := data
+	for len(rest) > 0 {
+		var v asn1.RawValue
+		r, err := asn1.Unmarshal(rest, &v)
+		if err != nil {
+			return tlvs, rest, err
+		}
+
+		tlvs = append(tlvs, v)
+		rest = r
+	}
+
+	return tlvs, nil, nil
+}
+
+func isEnumerated(v asn1.RawValue) bool {
+	return v.Class == asn1.ClassUniversal && v.Tag == asn1.TagEnum
+}
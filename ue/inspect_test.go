@@ -0,0 +1,80 @@
+package ue
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectTipoArquivo(t *testing.T) {
+	r, err := zip.OpenReader("test-data/o00407-0100700090001.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ext, err := DetectTipoArquivo(data)
+		if strings.HasSuffix(f.Name, ".logjez") {
+			if err == nil {
+				t.Error("expected unknown file", f.Name, ext)
+			}
+			continue
+		}
+
+		if err != nil || !strings.HasSuffix(f.Name, ext) {
+			t.Error("wrong type", f.Name, ext, err)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	s, ext, err := InspectFile("test-data/urna.bu")
+	if err != nil || ext != ".bu" {
+		t.Fatal(ext, err)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`{"Cabecalho":{"DataGeracao":"20221002T170509"`,
+		`"Fase":"Oficial"`,
+		`"TipoEnvelope":"EnvelopeBoletimUrna"`,
+		`"TipoUrna":"Secao"`,
+		`"TipoArquivo":"VotacaoUE"`,
+		`"DadosSecaoSA":{"DadosSecao":{`,
+		`"CodigoCargo":{"CargoConstitucional":"Deputado Federal"}`,
+		`"TipoVoto":"Nominal"`,
+		`"VersaoVotacao":"8.26.0.0 - Onça-pintada"`,
+		`"IdEleitoral":{"IDPleito":406}`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Error("missing", want)
+		}
+	}
+
+	s, ext, err = InspectFile("test-data/urna.rdv")
+	if err != nil || ext != ".rdv" {
+		t.Fatal(ext, err)
+	}
+
+	b, _ = json.Marshal(s)
+	if !strings.Contains(string(b), `"Eleicoes":{"EleicaoVota":[{"IdEleicao":544`) {
+		t.Error("expected resolved CHOICE", string(b[:200]))
+	}
+}
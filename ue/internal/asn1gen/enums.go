@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Go const and text of an ENUMERATED value, or of a value the module lacks
+// but the package uses (`extra`).
+type enumName struct {
+	typ     string
+	value   asnEnumValue
+	extra   bool
+	goConst string // Empty for the Go name of the value, see generator.enum.
+	text    string // Empty for the Go name of the value.
+}
+
+var extraValue = regexp.MustCompile(`^([a-z][A-Za-z0-9-]*)\((\d+)\)$`)
+
+// Reads `<Type>,<value>[,<const>[,<text>]]` rows; lines starting with `#` are
+// comments. A value written as `name(number)` is an extra value of the type.
+func readEnumNames(path string) ([]enumName, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+
+	var names []enumName
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(record) < 2 || len(record) > 4 {
+			line, _ := r.FieldPos(0)
+			return nil, fmt.Errorf("%s:%d: expected 2 to 4 fields", path, line)
+		}
+		record = append(record, "", "")
+
+		n := enumName{typ: record[0], value: asnEnumValue{name: record[1]}, goConst: record[2], text: record[3]}
+		if m := extraValue.FindStringSubmatch(record[1]); m != nil {
+			n.extra = true
+			n.value.name = m[1]
+			n.value.value, _ = strconv.Atoi(m[2])
+		}
+		names = append(names, n)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,447 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type generator struct {
+	pkg      string
+	sources  []string
+	declared map[string]bool     // Identifiers declared by hand in the package, see declaredNames.
+	types    map[string]*asnType // All assignments, by ASN.1 name.
+	names    map[string]bool     // Go identifiers declared so far.
+	consts   map[string]string   // Go const of each enum value, by "Type.value".
+	enums    map[string]enumName // Names given to enum values, by "Type.value".
+	extras   map[string][]asnEnumValue
+	imports  map[string]bool
+	buf      bytes.Buffer
+}
+
+func newGenerator(pkg string, sources []string, modules []*module, declared map[string]bool, enums []enumName) (*generator, error) {
+	g := &generator{
+		pkg:      pkg,
+		sources:  sources,
+		declared: declared,
+		types:    make(map[string]*asnType),
+		names:    make(map[string]bool),
+		consts:   make(map[string]string),
+		enums:    make(map[string]enumName),
+		extras:   make(map[string][]asnEnumValue),
+		imports:  make(map[string]bool),
+	}
+
+	for _, m := range modules {
+		for _, a := range m.assignments {
+			g.types[a.name] = a.typ
+			g.names[goName(a.name)] = true
+		}
+	}
+
+	for _, e := range enums {
+		t, ok := g.types[e.typ]
+		if !ok || t.kind != kindEnumerated {
+			return nil, fmt.Errorf("%s: not an ENUMERATED", e.typ)
+		}
+
+		found := false
+		for _, v := range t.values {
+			found = found || v.name == e.value.name
+		}
+		if found == e.extra {
+			return nil, fmt.Errorf("%s.%s: extra values must be new and other values known", e.typ, e.value.name)
+		}
+
+		if e.extra {
+			g.extras[e.typ] = append(g.extras[e.typ], e.value)
+		}
+		g.enums[e.typ+"."+e.value.name] = e
+		if len(e.goConst) > 0 {
+			g.names[e.goConst] = true
+		}
+	}
+
+	return g, nil
+}
+
+// Generates the Go source of all modules, in the order of their assignments.
+// Types declared by hand are skipped; CHOICEs skip each of their declarations
+// on its own, so that their interfaces can have extra methods.
+func (g *generator) generate(modules []*module) ([]byte, error) {
+	for _, m := range modules {
+		for _, a := range m.assignments {
+			if a.typ.kind != kindChoice && g.declared[goName(a.name)] {
+				continue
+			}
+
+			var err error
+			switch a.typ.kind {
+			case kindEnumerated:
+				err = g.enum(a)
+			case kindSequence:
+				err = g.sequence(a)
+			case kindChoice:
+				err = g.choice(a)
+			default:
+				err = g.named(a)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", a.name, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by asn1gen from %s; DO NOT EDIT.\n\n", strings.Join(g.sources, ", "))
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg)
+
+	var imports []string
+	for i := range g.imports {
+		imports = append(imports, i)
+	}
+	sort.Strings(imports)
+	if len(imports) > 0 {
+		out.WriteString("import (\n")
+		for _, i := range imports {
+			fmt.Fprintf(&out, "\t%q\n", i)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return out.Bytes(), err
+	}
+
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) doc(lines []string) {
+	for _, l := range lines {
+		g.printf("// %s\n", l)
+	}
+}
+
+// INTEGER, strings, OCTET STRING, BOOLEAN, SEQUENCE OF and references.
+func (g *generator) named(a assignment) error {
+	t, err := g.goType(a.typ, true)
+	if err != nil {
+		return err
+	}
+
+	g.doc(a.doc)
+	g.printf("type %s %s", goName(a.name), t)
+	if len(a.comment) > 0 {
+		g.printf(" // %s", a.comment)
+	}
+	g.printf("\n\n")
+	return nil
+}
+
+// Consts are the Go names of the values, suffixed with the type when taken,
+// unless named otherwise in the enum names. `String` returns their texts,
+// by default also the Go names of the values.
+func (g *generator) enum(a assignment) error {
+	name := goName(a.name)
+	values := append(append([]asnEnumValue{}, a.typ.values...), g.extras[a.name]...)
+	sort.SliceStable(values, func(i, j int) bool { return values[i].value < values[j].value })
+
+	underlying := "byte"
+	contiguous := true
+	var numbers []string
+	for i, v := range values {
+		if v.value < 0 || v.value > 0xff {
+			underlying = "int"
+		}
+		contiguous = contiguous && v.value == i+1
+		numbers = append(numbers, fmt.Sprint(v.value))
+	}
+
+	g.doc(a.doc)
+	g.printf("type %s %s\n\nconst (\n", name, underlying)
+	var texts []string
+	for _, v := range values {
+		n := g.enums[a.name+"."+v.name]
+		c := n.goConst
+		if len(c) == 0 {
+			c = goName(v.name)
+			if g.names[c] || g.declared[c] {
+				c += name
+			}
+		}
+		g.names[c] = true
+		g.consts[a.name+"."+v.name] = c
+
+		text := n.text
+		if len(text) == 0 {
+			text = goName(v.name)
+		}
+		texts = append(texts, fmt.Sprintf("%q", text))
+
+		g.printf("%s %s = %d", c, name, v.value)
+		if len(v.comment) > 0 {
+			g.printf(" // %s", v.comment)
+		}
+		g.printf("\n")
+	}
+	g.printf(")\n\n")
+
+	g.imports["errors"] = true
+	g.imports["fmt"] = true
+	g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+	g.printf("// Value of the contents octets of an implicitly tagged %s.\n", a.name)
+	g.printf("func %sFromData(data []byte) (%s, error) {\n", name, name)
+	g.printf("if len(data) == 0 || len(data) > 4 {\nreturn 0, errors.New(\"invalid data\")\n}\n\n")
+	g.printf("var e asn1.Enumerated\nfor _, b := range data {\ne = e<<8 | asn1.Enumerated(b)\n}\n\nreturn %sFromEnumerated(e)\n}\n\n", name)
+
+	g.printf("func %sFromEnumerated(e asn1.Enumerated) (%s, error) {\n", name, name)
+	g.printf("switch e {\ncase %s:\nreturn %s(e), nil\n}\n\n", strings.Join(numbers, ", "), name)
+	g.printf("return 0, errors.New(\"invalid data\")\n}\n\n")
+
+	g.printf("func (v %s) String() string {\n", name)
+	if contiguous {
+		g.printf("if v >= 1 && v <= %d {\nreturn [...]string{%s}[v-1]\n}\n\n", len(values), strings.Join(texts, ", "))
+	} else {
+		g.printf("switch v {\n")
+		for i, v := range values {
+			g.printf("case %s:\nreturn %s\n", g.consts[a.name+"."+v.name], texts[i])
+		}
+		g.printf("}\n\n")
+	}
+	g.printf("return fmt.Sprintf(\"%%T(%%d)\", v, v)\n}\n\n")
+
+	return nil
+}
+
+func (g *generator) sequence(a assignment) error {
+	g.doc(a.doc)
+	g.printf("type %s struct {\n", goName(a.name))
+	for _, f := range a.typ.fields {
+		t, err := g.goType(f.typ, false)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+
+		g.printf("%s %s", goName(f.name), t)
+
+		var params []string
+		if f.tag >= 0 {
+			params = append(params, fmt.Sprintf("tag:%d", f.tag))
+			if f.explicit {
+				params = append(params, "explicit")
+			}
+		}
+		if f.optional {
+			params = append(params, "optional")
+		}
+		if p, ok := stringParams[g.resolve(f.typ).str]; ok {
+			params = append(params, p)
+		}
+		if len(params) > 0 {
+			g.printf(" `asn1:\"%s\"`", strings.Join(params, ","))
+		}
+
+		if len(f.comment) > 0 {
+			g.printf(" // %s", f.comment)
+		}
+		g.printf("\n")
+	}
+	g.printf("}\n\n")
+
+	return nil
+}
+
+// A sealed interface implemented by the variants and a reader from the
+// RawValue of the fields holding the CHOICE; variants without a type of
+// their own (e.g. SEQUENCE OF) make the reader return `any` instead.
+func (g *generator) choice(a assignment) error {
+	name := goName(a.name)
+
+	sealed := true
+	var variants []string
+	for _, f := range a.typ.fields {
+		if f.tag < 0 {
+			return fmt.Errorf("%s: untagged CHOICE variant", f.name)
+		}
+
+		t, err := g.variantType(f.typ)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		variants = append(variants, t)
+
+		if f.typ.kind != kindRef || g.types[f.typ.ref] == nil {
+			sealed = false
+		}
+	}
+
+	result := "any"
+	if sealed {
+		result = name
+
+		if !g.declared[name] {
+			g.doc(a.doc)
+			g.printf("type %s interface {\nis%s()\n}\n\n", name, name)
+		}
+		done := make(map[string]bool)
+		for _, v := range variants {
+			if !done[v] && !g.declared[v+".is"+name] {
+				g.printf("func (%s) is%s() {}\n", v, name)
+			}
+			done[v] = true
+		}
+		g.printf("\n")
+	}
+	if g.declared["read"+name] {
+		return nil
+	}
+
+	g.imports["errors"] = true
+	g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+	if !sealed || g.declared[name] {
+		g.doc(a.doc)
+	}
+	g.printf("// Result is one of (%s)\n", strings.Join(variants, ", "))
+	g.printf("func read%s(raw asn1.RawValue) (%s, error) {\nswitch raw.Tag {\n", name, result)
+	for i, f := range a.typ.fields {
+		g.printf("case %d:\n", f.tag)
+		err := g.readVariant(f, variants[i])
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	g.printf("}\n\nreturn nil, errors.New(\"could not read %s\")\n}\n\n", name)
+
+	return nil
+}
+
+func (g *generator) readVariant(f asnField, t string) error {
+	resolved := g.resolve(f.typ)
+
+	switch {
+	case resolved.kind == kindChoice:
+		// A CHOICE is always explicitly tagged.
+		g.printf("var inner asn1.RawValue\n_, err := asn1.Unmarshal(raw.Bytes, &inner)\nif err != nil {\nreturn nil, err\n}\n")
+		g.printf("v, err := read%s(inner)\n", t)
+	case f.explicit:
+		g.printf("var v %s\n_, err := asn1.Unmarshal(raw.Bytes, &v)\n", t)
+	case resolved.kind == kindSequence:
+		g.printf("var v %s\nerr := FillSequence(raw.Bytes, &v)\n", t)
+	case resolved.kind == kindSequenceOf:
+		g.printf("var v %s\nerr := FillSlice(raw.Bytes, &v)\n", t)
+	case resolved.kind == kindEnumerated && f.typ.kind == kindRef:
+		g.printf("v, err := %sFromData(raw.Bytes)\n", t)
+	case resolved.kind == kindInteger:
+		g.printf("var n int\n_, err := asn1.UnmarshalWithParams(raw.FullBytes, &n, \"tag:%d\")\n", f.tag)
+		g.printf("if err != nil {\nreturn nil, err\n}\nreturn %s(n), nil\n", t)
+		return nil
+	case resolved.kind == kindString, resolved.kind == kindOctetString:
+		g.printf("return %s(raw.Bytes), nil\n", t)
+		return nil
+	default:
+		return fmt.Errorf("unsupported CHOICE variant")
+	}
+
+	g.printf("if err != nil {\nreturn nil, err\n}\nreturn v, nil\n")
+	return nil
+}
+
+// Go type of a variant: its own name, or the Go type of inline types.
+func (g *generator) variantType(t *asnType) (string, error) {
+	if t.kind == kindRef {
+		return goName(t.ref), nil
+	}
+
+	return g.goType(t, true)
+}
+
+// Field parameters of the string types that are not PrintableString, the
+// default of the encoder; decoding accepts any of them.
+var stringParams = map[string]string{
+	"IA5String":     "ia5",
+	"NumericString": "numeric",
+	"UTF8String":    "utf8",
+}
+
+// Follows references to the type they name; unknown references are kept.
+func (g *generator) resolve(t *asnType) *asnType {
+	for i := 0; t.kind == kindRef && i < 16; i++ {
+		r, ok := g.types[t.ref]
+		if !ok {
+			return t
+		}
+		t = r
+	}
+
+	return t
+}
+
+// Go type of a field, or of a named type when `declaration` is set. Fields of
+// ENUMERATED types are asn1.Enumerated and fields of CHOICE types are
+// asn1.RawValue, decoded by the `<Type>FromData` and `read<Type>` functions.
+func (g *generator) goType(t *asnType, declaration bool) (string, error) {
+	switch t.kind {
+	case kindInteger:
+		return "int", nil
+	case kindString:
+		return "string", nil
+	case kindOctetString:
+		return "[]byte", nil
+	case kindBoolean:
+		return "bool", nil
+	case kindEnumerated:
+		g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+		return "asn1.Enumerated", nil
+	case kindChoice:
+		g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+		return "asn1.RawValue", nil
+	case kindSequenceOf:
+		elem, err := g.goType(t.elem, false)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case kindRef:
+		if !declaration {
+			switch g.resolve(t).kind {
+			case kindEnumerated:
+				g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+				return "asn1.Enumerated", nil
+			case kindChoice:
+				g.imports["github.com/google/certificate-transparency-go/asn1"] = true
+				return "asn1.RawValue", nil
+			}
+		}
+		return goName(t.ref), nil
+	}
+
+	return "", fmt.Errorf("inline SEQUENCE is not supported; declare it as a type")
+}
+
+// Exported Go identifier: `numero-serie` and `numeroSerie` become `NumeroSerie`.
+func goName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,194 @@
+// Generates the Go types of ASN.1 modules: structs for SEQUENCEs, consts with
+// `<Type>FromData`, `<Type>FromEnumerated` and `String` for ENUMERATEDs, and
+// sealed interfaces with `read<Type>` decoders for CHOICEs.
+//
+//	go run ./internal/asn1gen [-pkg <name>] [-o <file>] [-enums <file.csv>] <file.asn1|dir> ...
+//
+// Directories are searched for `*.asn1` files; without any module nothing is
+// written. Declarations already written by hand in the other Go files of the
+// output's directory are not generated, so that CHOICEs can have interfaces
+// with extra methods.
+//
+// The `-enums` file names the consts and texts of ENUMERATED values, in
+// `<Type>,<value>[,<const>[,<text>]]` rows; values the modules lack are
+// written as `name(number)`.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	gotoken "go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("asn1gen", flag.ContinueOnError)
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "Package of the generated file")
+	out := flags.String("o", "types_gen.go", "Generated file")
+	enums := flags.String("enums", "", "CSV file with the Go consts and texts of ENUMERATED values")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if len(flags.Args()) == 0 || len(*pkg) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: asn1gen [-pkg <name>] [-o <file>] [-enums <file.csv>] <file.asn1|dir> ...")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	paths, err := modulePaths(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "asn1gen: no ASN.1 modules found; nothing generated")
+		return 0
+	}
+
+	declared, err := declaredNames(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var names []enumName
+	if len(*enums) > 0 {
+		names, err = readEnumNames(*enums)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	src, err := generateFiles(*pkg, paths, declared, names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	err = os.WriteFile(*out, src, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+// Files given and the `*.asn1` files of directories given, sorted.
+func modulePaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if os.IsNotExist(err) && filepath.Ext(arg) != ".asn1" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(arg, "*.asn1"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+
+	return paths, nil
+}
+
+// Top-level identifiers of the non-test Go files next to `out`, other than
+// `out` itself; methods are keyed as "Type.Method".
+func declaredNames(out string) (map[string]bool, error) {
+	declared := make(map[string]bool)
+	fset := gotoken.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(filepath.Dir(out), "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if filepath.Base(path) == filepath.Base(out) || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		f, err := goparser.ParseFile(fset, path, nil, goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					declared[d.Name.Name] = true
+					continue
+				}
+
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					declared[id.Name+"."+d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						declared[s.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, n := range s.Names {
+							declared[n.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return declared, nil
+}
+
+func generateFiles(pkg string, paths []string, declared map[string]bool, enums []enumName) ([]byte, error) {
+	var modules []*module
+	var sources []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := parseModule(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		modules = append(modules, m)
+		sources = append(sources, filepath.Base(path))
+	}
+
+	g, err := newGenerator(pkg, sources, modules, declared, enums)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.generate(modules)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src, err := generateFiles("ue", []string{"test-data/exemplo.asn1"}, nil, nil)
+	if err != nil {
+		t.Fatal(err, string(src))
+	}
+
+	for _, s := range []string{
+		"// Code generated by asn1gen from exemplo.asn1; DO NOT EDIT.",
+		"type DataHoraJE string // Data e hora no formato YYYYMMDDThhmmss.",
+		"type NumeroSerieFlash []byte",
+		"// Fase em que foi gerado o arquivo.\ntype Fase byte",
+		"Simulado    Fase = 1 // Simulado.",
+		"VicePresidente CargoConstitucional = 2",
+		"NominalTipoVotoRdv TipoVotoRdv = 2",
+		"func FaseFromData(data []byte) (Fase, error) {",
+		"func (v Fase) String() string {",
+		"type IdEleitoral interface {",
+		"func (IDPleito) isIdEleitoral()",
+		"func readIdEleitoral(raw asn1.RawValue) (IdEleitoral, error) {",
+		`_, err := asn1.UnmarshalWithParams(raw.FullBytes, &n, "tag:3")`,
+		"v, err := CargoConstitucionalFromData(raw.Bytes)",
+		"var v NumeroCargoConsultaLivre\n\t\t_, err := asn1.Unmarshal(raw.Bytes, &v)",
+		"// Votos das eleições.\n// Result is one of ([]EleicaoVota, []EleicaoVota)\nfunc readEleicoes(raw asn1.RawValue) (any, error) {",
+		"err := FillSlice(raw.Bytes, &v)",
+		"err := FillSequence(raw.Bytes, &v)",
+		"Municipio int // Código do município.",
+		"Cargo     asn1.RawValue // Cargo votado.",
+		"IdEleitoral   asn1.RawValue // Identificador eleitoral.",
+		"Fase          asn1.Enumerated",
+		"QtdEleitores  int           `asn1:\"tag:1,optional\"` // Quantidade de eleitores.",
+		"Eleicoes      []EleicaoVota `asn1:\"tag:2\"`",
+		"Valido        bool   `asn1:\"optional\"`",
+		"Digitacao     string `asn1:\"optional,numeric\"`",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("expected %q", s)
+		}
+	}
+
+	if strings.Contains(string(src), "type Eleicoes interface") {
+		t.Error("CHOICE of SEQUENCE OF variants cannot be sealed")
+	}
+	if t.Failed() {
+		t.Log(string(src))
+	}
+}
+
+func TestGenerateDeclared(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "types.go"), []byte(`package ue
+
+type Fase byte
+
+type IdEleitoral interface {
+	Pleito() int
+	isIdEleitoral()
+}
+
+func (IDPleito) isIdEleitoral() {}
+
+func readEleicoes() {}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	declared, err := declaredNames(filepath.Join(dir, "types_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateFiles("ue", []string{"test-data/exemplo.asn1"}, declared, nil)
+	if err != nil {
+		t.Fatal(err, string(src))
+	}
+
+	for _, s := range []string{
+		"type CargoConstitucional byte",
+		"func (IDEleicao) isIdEleitoral()",
+		"func readIdEleitoral(raw asn1.RawValue) (IdEleitoral, error) {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("expected %q", s)
+		}
+	}
+	for _, s := range []string{
+		"type Fase byte",
+		"func FaseFromData",
+		"type IdEleitoral interface",
+		"func (IDPleito) isIdEleitoral()",
+		"func readEleicoes",
+	} {
+		if strings.Contains(string(src), s) {
+			t.Errorf("unexpected %q", s)
+		}
+	}
+	if t.Failed() {
+		t.Log(string(src))
+	}
+}
+
+func TestGenerateEnumNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "enums.csv")
+	err := os.WriteFile(path, []byte(`# Comentário.
+Fase,oficial,,Eleição oficial
+TipoVotoRdv,legenda,LegendaRdv
+TipoVotoRdv,invalido(255),,Invalido
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := readEnumNames(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generateFiles("ue", []string{"test-data/exemplo.asn1"}, nil, names)
+	if err != nil {
+		t.Fatal(err, string(src))
+	}
+
+	for _, s := range []string{
+		`if v >= 1 && v <= 3 {`,
+		`[...]string{"Simulado", "Eleição oficial", "Treinamento"}[v-1]`,
+		"LegendaRdv         TipoVotoRdv = 1",
+		"Invalido           TipoVotoRdv = 255",
+		"case 1, 2, 99, 255:",
+		"case Invalido:\n\t\treturn \"Invalido\"",
+		`return fmt.Sprintf("%T(%d)", v, v)`,
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("expected %q", s)
+		}
+	}
+	if t.Failed() {
+		t.Log(string(src))
+	}
+
+	for _, n := range [][]enumName{
+		{{typ: "Fase", value: asnEnumValue{name: "outra"}}},
+		{{typ: "Fase", value: asnEnumValue{name: "oficial", value: 2}, extra: true}},
+		{{typ: "IDPleito", value: asnEnumValue{name: "oficial"}}},
+	} {
+		if _, err := generateFiles("ue", []string{"test-data/exemplo.asn1"}, nil, n); err == nil {
+			t.Error("expected error", n)
+		}
+	}
+}
+
+func TestRunWithoutModules(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "types_gen.go")
+	if code := run([]string{"-pkg", "ue", "-o", out, "spec"}); code != 0 {
+		t.Error("wrong exit code", code)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("expected no output", err)
+	}
+
+	if code := run([]string{"-pkg", "ue", "-o", out, "test-data"}); code != 0 {
+		t.Error("wrong exit code", code)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"M DEFINITIONS ::= BEGIN X ::= SEQUENCE { a INTEGER",
+		"M DEFINITIONS ::= BEGIN X ::= ENUMERATED { a (x) } END",
+		"M BEGIN END",
+	} {
+		if _, err := parseModule(src); err == nil {
+			t.Error("expected error", src)
+		}
+	}
+}
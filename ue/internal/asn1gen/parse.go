@@ -0,0 +1,505 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokSymbol
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+type typeKind int
+
+const (
+	kindInteger typeKind = iota
+	kindString
+	kindOctetString
+	kindBoolean
+	kindEnumerated
+	kindSequence
+	kindSequenceOf
+	kindChoice
+	kindRef
+)
+
+type asnType struct {
+	kind   typeKind
+	ref    string         // Referenced type (kindRef).
+	str    string         // ASN.1 string type, e.g. NumericString (kindString).
+	elem   *asnType       // Item type (kindSequenceOf).
+	fields []asnField     // Components (kindSequence) or variants (kindChoice).
+	values []asnEnumValue // Values (kindEnumerated).
+}
+
+type asnField struct {
+	name     string
+	tag      int // -1 if untagged.
+	explicit bool
+	optional bool
+	typ      *asnType
+	comment  string
+}
+
+type asnEnumValue struct {
+	name    string
+	value   int
+	comment string
+}
+
+type assignment struct {
+	name    string
+	typ     *asnType
+	doc     []string
+	comment string // Comment at the end of the line, for single-line types.
+}
+
+type module struct {
+	name         string
+	explicitTags bool // Tagging default of the module (EXPLICIT unless IMPLICIT or AUTOMATIC TAGS).
+	assignments  []assignment
+}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	comments map[int]string // Comment text by line.
+	code     map[int]bool   // Lines with tokens other than comments.
+	module   *module
+}
+
+// Parses the subset of ASN.1 used by the TSE modules: type assignments of
+// INTEGER, ENUMERATED, SEQUENCE (OF), CHOICE, strings, OCTET STRING and
+// BOOLEAN, with tags, OPTIONAL and `--` comments; constraints are ignored.
+func parseModule(src string) (*module, error) {
+	p := &parser{comments: make(map[int]string), code: make(map[int]bool)}
+	err := p.lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p.module = &module{explicitTags: true}
+	p.module.name, err = p.ident()
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.accept("DEFINITIONS") {
+		if p.peek().kind == tokEOF {
+			return nil, p.errorf("expected DEFINITIONS")
+		}
+		p.next()
+	}
+	for !p.accept("::=") {
+		switch p.next().text {
+		case "IMPLICIT", "AUTOMATIC":
+			p.module.explicitTags = false
+		case "":
+			return nil, p.errorf("expected ::=")
+		}
+	}
+	err = p.expect("BEGIN")
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.accept("END") {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return nil, p.errorf("expected END")
+		case t.text == "IMPORTS" || t.text == "EXPORTS":
+			for p.next().text != ";" {
+				if p.peek().kind == tokEOF {
+					return nil, p.errorf("expected ;")
+				}
+			}
+			continue
+		}
+
+		a, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		p.module.assignments = append(p.module.assignments, a)
+	}
+
+	return p.module, nil
+}
+
+func (p *parser) lex(src string) error {
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "--"):
+			// Until the next `--` or the end of the line.
+			j := i + 2
+			for j < len(src) && src[j] != '\n' && !strings.HasPrefix(src[j:], "--") {
+				j++
+			}
+			p.addComment(line, src[i+2:j])
+			i = j
+			if strings.HasPrefix(src[i:], "--") {
+				i += 2
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			j := strings.Index(src[i:], "*/")
+			if j < 0 {
+				return fmt.Errorf("line %d: unterminated comment", line)
+			}
+			for _, l := range strings.Split(src[i+2:i+j], "\n") {
+				p.addComment(line, l)
+				line++
+			}
+			line--
+			i += j + 2
+		case isLetter(c):
+			j := i + 1
+			for j < len(src) && (isLetter(src[j]) || isDigit(src[j]) || src[j] == '-' && !strings.HasPrefix(src[j:], "--")) {
+				j++
+			}
+			p.addToken(tokIdent, src[i:j], line)
+			i = j
+		case isDigit(c) || c == '-' && i+1 < len(src) && isDigit(src[i+1]):
+			j := i + 1
+			for j < len(src) && isDigit(src[j]) {
+				j++
+			}
+			p.addToken(tokNumber, src[i:j], line)
+			i = j
+		default:
+			n := 1
+			for _, s := range []string{"::=", "...", ".."} {
+				if strings.HasPrefix(src[i:], s) {
+					n = len(s)
+					break
+				}
+			}
+			p.addToken(tokSymbol, src[i:i+n], line)
+			i += n
+		}
+	}
+	p.tokens = append(p.tokens, token{tokEOF, "", line})
+
+	return nil
+}
+
+func (p *parser) addToken(kind tokenKind, text string, line int) {
+	p.tokens = append(p.tokens, token{kind, text, line})
+	p.code[line] = true
+}
+
+func (p *parser) addComment(line int, text string) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return
+	}
+
+	if c, ok := p.comments[line]; ok {
+		text = c + " " + text
+	}
+	p.comments[line] = text
+}
+
+// Comment lines right above `line`.
+func (p *parser) docAbove(line int) []string {
+	var doc []string
+	for l := line - 1; !p.code[l]; l-- {
+		c, ok := p.comments[l]
+		if !ok {
+			break
+		}
+		doc = append([]string{c}, doc...)
+	}
+
+	return doc
+}
+
+// Comment at the end of `line` or, failing that, right above it.
+func (p *parser) commentOf(line int) string {
+	if c, ok := p.comments[line]; ok {
+		return c
+	}
+
+	return strings.Join(p.docAbove(line), " ")
+}
+
+func (p *parser) assignment() (assignment, error) {
+	line := p.peek().line
+	name, err := p.ident()
+	if err != nil {
+		return assignment{}, err
+	}
+
+	err = p.expect("::=")
+	if err != nil {
+		return assignment{}, err
+	}
+
+	t, err := p.typ()
+	if err != nil {
+		return assignment{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	a := assignment{name: name, typ: t, doc: p.docAbove(line)}
+	switch t.kind {
+	case kindSequence, kindChoice, kindEnumerated:
+	default:
+		a.comment = p.comments[line]
+	}
+
+	return a, nil
+}
+
+func (p *parser) typ() (*asnType, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return nil, fmt.Errorf("line %d: expected type, found %q", t.line, t.text)
+	}
+
+	var typ *asnType
+	switch t.text {
+	case "INTEGER":
+		typ = &asnType{kind: kindInteger}
+		if p.peek().text == "{" {
+			// Named numbers.
+			p.skipBalanced("{", "}")
+		}
+	case "BOOLEAN":
+		typ = &asnType{kind: kindBoolean}
+	case "OCTET":
+		err := p.expect("STRING")
+		if err != nil {
+			return nil, err
+		}
+		typ = &asnType{kind: kindOctetString}
+	case "GeneralString", "VisibleString", "PrintableString", "NumericString", "UTF8String", "IA5String", "GraphicString":
+		typ = &asnType{kind: kindString, str: t.text}
+	case "ENUMERATED":
+		values, err := p.enumValues()
+		if err != nil {
+			return nil, err
+		}
+		typ = &asnType{kind: kindEnumerated, values: values}
+	case "SEQUENCE":
+		p.skipConstraint()
+		if p.accept("SIZE") {
+			p.skipConstraint()
+		}
+		if p.accept("OF") {
+			elem, err := p.typ()
+			if err != nil {
+				return nil, err
+			}
+			return &asnType{kind: kindSequenceOf, elem: elem}, nil
+		}
+
+		fields, err := p.fields()
+		if err != nil {
+			return nil, err
+		}
+		typ = &asnType{kind: kindSequence, fields: fields}
+	case "CHOICE":
+		fields, err := p.fields()
+		if err != nil {
+			return nil, err
+		}
+		typ = &asnType{kind: kindChoice, fields: fields}
+	default:
+		if !unicode.IsUpper(rune(t.text[0])) {
+			return nil, fmt.Errorf("line %d: expected type, found %q", t.line, t.text)
+		}
+		typ = &asnType{kind: kindRef, ref: t.text}
+	}
+
+	p.skipConstraint()
+	return typ, nil
+}
+
+func (p *parser) enumValues() ([]asnEnumValue, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	var values []asnEnumValue
+	next := 0
+	for !p.accept("}") {
+		if p.accept("...") || p.accept(",") {
+			continue
+		}
+
+		line := p.peek().line
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+
+		v := next
+		if p.accept("(") {
+			n := p.next()
+			if n.kind != tokNumber {
+				return nil, fmt.Errorf("line %d: expected number, found %q", n.line, n.text)
+			}
+			v, _ = strconv.Atoi(n.text)
+
+			err = p.expect(")")
+			if err != nil {
+				return nil, err
+			}
+		}
+		next = v + 1
+
+		values = append(values, asnEnumValue{name: name, value: v, comment: p.commentOf(line)})
+	}
+
+	return values, nil
+}
+
+func (p *parser) fields() ([]asnField, error) {
+	err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []asnField
+	for !p.accept("}") {
+		if p.accept("...") || p.accept(",") {
+			continue
+		}
+
+		line := p.peek().line
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+
+		f := asnField{name: name, tag: -1, explicit: p.module.explicitTags}
+		if p.accept("[") {
+			n := p.next()
+			if n.kind != tokNumber {
+				return nil, fmt.Errorf("line %d: expected tag number, found %q", n.line, n.text)
+			}
+			f.tag, _ = strconv.Atoi(n.text)
+
+			err = p.expect("]")
+			if err != nil {
+				return nil, err
+			}
+		}
+		if p.accept("IMPLICIT") {
+			f.explicit = false
+		} else if p.accept("EXPLICIT") {
+			f.explicit = true
+		}
+
+		f.typ, err = p.typ()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if p.accept("OPTIONAL") {
+			f.optional = true
+		} else if p.accept("DEFAULT") {
+			f.optional = true
+			p.next()
+		}
+		f.comment = p.commentOf(line)
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+func (p *parser) skipConstraint() {
+	if p.peek().text == "(" {
+		p.skipBalanced("(", ")")
+	}
+}
+
+func (p *parser) skipBalanced(open, close string) {
+	depth := 0
+	for {
+		t := p.next()
+		switch t.text {
+		case open:
+			depth++
+		case close:
+			depth--
+		}
+		if depth == 0 || t.kind == tokEOF {
+			return
+		}
+	}
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) accept(text string) bool {
+	if p.peek().kind != tokEOF && p.peek().text == text {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.errorf("expected %s, found %q", text, p.peek().text)
+	}
+
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", fmt.Errorf("line %d: expected identifier, found %q", t.line, t.text)
+	}
+
+	return t.text, nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.peek().line, fmt.Sprintf(format, args...))
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,181 @@
+// Fills the IBGE codes of the embedded municipio table from a TSE/IBGE
+// correspondence file, e.g. the one of the TSE open data portal:
+//
+//	go run ./internal/ibge [-o resource/<ano>/municipios.csv] <correspondencia.csv>
+//
+// The file is a CSV, separated by `,` or `;`, whose header names a TSE code
+// column and an IBGE code column (e.g. CD_MUNICIPIO_TSE and
+// CD_MUNICIPIO_IBGE). Codes whose UF prefix does not match the UF of the
+// municipio are rejected. Exits with 1 while any municipio lacks a code.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// First two digits of the IBGE codes of each UF.
+var codigosUf = map[string]int{
+	"RO": 11, "AC": 12, "AM": 13, "RR": 14, "PA": 15, "AP": 16, "TO": 17,
+	"MA": 21, "PI": 22, "CE": 23, "RN": 24, "PB": 25, "PE": 26, "AL": 27, "SE": 28, "BA": 29,
+	"MG": 31, "ES": 32, "RJ": 33, "SP": 35,
+	"PR": 41, "SC": 42, "RS": 43,
+	"MS": 50, "MT": 51, "GO": 52, "DF": 53,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("ibge", flag.ContinueOnError)
+	out := flags.String("o", "resource/2022/municipios.csv", "Municipio table to fill")
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if len(flags.Args()) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: ibge [-o <municipios.csv>] <correspondencia.csv>")
+		flags.PrintDefaults()
+		return 2
+	}
+
+	codigos, err := readCorrespondencia(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	missing, err := fill(*out, codigos)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "ibge: %d municipios without IBGE code\n", missing)
+		return 1
+	}
+
+	return 0
+}
+
+// IBGE codes by TSE code.
+func readCorrespondencia(path string) (map[int]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	first, _, _ := bytes.Cut(data, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	tse, ibge := -1, -1
+	for i, h := range header {
+		h = strings.ToUpper(strings.TrimSpace(h))
+		if tse < 0 && strings.Contains(h, "TSE") {
+			tse = i
+		}
+		if ibge < 0 && strings.Contains(h, "IBGE") {
+			ibge = i
+		}
+	}
+	if tse < 0 || ibge < 0 {
+		return nil, fmt.Errorf("%s: no TSE and IBGE code columns in %v", path, header)
+	}
+
+	codigos := make(map[int]int)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(record) <= tse || len(record) <= ibge {
+			continue
+		}
+
+		t, err := strconv.Atoi(strings.TrimSpace(record[tse]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: TSE code %q: %w", path, record[tse], err)
+		}
+		i, err := strconv.Atoi(strings.TrimSpace(record[ibge]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: IBGE code %q: %w", path, record[ibge], err)
+		}
+		codigos[t] = i
+	}
+
+	return codigos, nil
+}
+
+// Rewrites the `codigo TSE,nome,UF[,codigo IBGE]` table with the codes found;
+// returns the number of municipios left without one.
+func fill(path string, codigos map[int]int) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("%s: empty municipio table", path)
+	}
+
+	missing := 0
+	for i, record := range records {
+		if len(record) < 3 {
+			return 0, fmt.Errorf("%s:%d: expected at least 3 fields", path, i+1)
+		}
+
+		tse, err := strconv.Atoi(record[0])
+		if err != nil {
+			return 0, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+
+		ibge, ok := codigos[tse]
+		if !ok {
+			if len(record) < 4 || len(record[3]) == 0 {
+				missing++
+			}
+			continue
+		}
+
+		if ibge < 1000000 || ibge > 9999999 || ibge/100000 != codigosUf[record[2]] {
+			return 0, fmt.Errorf("%s:%d: IBGE code %d is not of a municipio of %s", path, i+1, ibge, record[2])
+		}
+		records[i] = append(record[:3], strconv.Itoa(ibge))
+	}
+
+	var buf bytes.Buffer
+	err = csv.NewWriter(&buf).WriteAll(records)
+	if err != nil {
+		return 0, err
+	}
+
+	return missing, os.WriteFile(path, buf.Bytes(), 0644)
+}
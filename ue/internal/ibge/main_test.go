@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	table := filepath.Join(dir, "municipios.csv")
+	correspondencia := filepath.Join(dir, "correspondencia.csv")
+	write := func(path, data string) {
+		err := os.WriteFile(path, []byte(data), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(table, "1007,BUJARI,AC\n1120,ACRELANDIA,AC,1200013\n88986,ACEGUA,RS\n")
+	write(correspondencia, "\"CD_MUNICIPIO_TSE\";\"NM_MUNICIPIO\";\"CD_MUNICIPIO_IBGE\"\n\"1007\";\"BUJARI\";\"1200138\"\n")
+	if code := run([]string{"-o", table, correspondencia}); code != 1 {
+		t.Error("expected exit code 1 for ACEGUA without code, got", code)
+	}
+
+	write(correspondencia, "codigo_tse,codigo_ibge\n88986,4300034\n")
+	if code := run([]string{"-o", table, correspondencia}); code != 0 {
+		t.Error("wrong exit code", code)
+	}
+
+	data, err := os.ReadFile(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); s != "1007,BUJARI,AC,1200138\n1120,ACRELANDIA,AC,1200013\n88986,ACEGUA,RS,4300034\n" {
+		t.Error("wrong table", s)
+	}
+
+	write(correspondencia, "codigo_tse,codigo_ibge\n88986,1200138\n")
+	if code := run([]string{"-o", table, correspondencia}); code != 1 {
+		t.Error("expected exit code 1 for a code of another UF, got", code)
+	}
+}
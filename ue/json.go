@@ -0,0 +1,830 @@
+package ue
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/certificate-transparency-go/asn1"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// The ASN.1 entities marshal to JSON as Inspect shows them (enum names,
+// decoded CHOICE variants, ISO-8859-1 text as UTF-8), except that byte fields
+// (hashes, signatures, envelope contents) are hex and enums are named only
+// when the name is unambiguous. Unmarshalling re-encodes CHOICE variants as
+// the TSE files do (strings as GeneralString), so the result equals the
+// entity decoded from the file.
+
+func (c CabecalhoEntidade) MarshalJSON() ([]byte, error) {
+	return marshalEntity(c)
+}
+
+func (c *CabecalhoEntidade) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, c)
+}
+
+func (u Urna) MarshalJSON() ([]byte, error) {
+	return marshalEntity(u)
+}
+
+func (u *Urna) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, u)
+}
+
+func (eeg EntidadeEnvelopeGenerico) MarshalJSON() ([]byte, error) {
+	return marshalEntity(eeg)
+}
+
+func (eeg *EntidadeEnvelopeGenerico) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, eeg)
+}
+
+func (s Seguranca) MarshalJSON() ([]byte, error) {
+	return marshalEntity(s)
+}
+
+func (s *Seguranca) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, s)
+}
+
+func (b EntidadeBoletimUrna) MarshalJSON() ([]byte, error) {
+	return marshalEntity(b)
+}
+
+func (b *EntidadeBoletimUrna) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, b)
+}
+
+func (a ApuracaoNormal) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *ApuracaoNormal) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a ApuracaoEletronica) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *ApuracaoEletronica) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a ApuracaoMistaBUAE) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *ApuracaoMistaBUAE) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a ApuracaoMistaMR) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *ApuracaoMistaMR) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a ApuracaoTotalmenteManualDigitacaoAE) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *ApuracaoTotalmenteManualDigitacaoAE) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (c Carga) MarshalJSON() ([]byte, error) {
+	return marshalEntity(c)
+}
+
+func (c *Carga) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, c)
+}
+
+func (cr CorrespondenciaResultado) MarshalJSON() ([]byte, error) {
+	return marshalEntity(cr)
+}
+
+func (cr *CorrespondenciaResultado) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, cr)
+}
+
+func (d DadosSA) MarshalJSON() ([]byte, error) {
+	return marshalEntity(d)
+}
+
+func (d *DadosSA) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, d)
+}
+
+func (d DadosSecao) MarshalJSON() ([]byte, error) {
+	return marshalEntity(d)
+}
+
+func (d *DadosSecao) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, d)
+}
+
+func (h HistoricoVotoImpresso) MarshalJSON() ([]byte, error) {
+	return marshalEntity(h)
+}
+
+func (h *HistoricoVotoImpresso) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, h)
+}
+
+func (id IdentificacaoContingencia) MarshalJSON() ([]byte, error) {
+	return marshalEntity(id)
+}
+
+func (id *IdentificacaoContingencia) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, id)
+}
+
+func (id IdentificacaoMesaJustificativa) MarshalJSON() ([]byte, error) {
+	return marshalEntity(id)
+}
+
+func (id *IdentificacaoMesaJustificativa) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, id)
+}
+
+func (id IdentificacaoSecaoEleitoral) MarshalJSON() ([]byte, error) {
+	return marshalEntity(id)
+}
+
+func (id *IdentificacaoSecaoEleitoral) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, id)
+}
+
+func (iv IdentificacaoVotavel) MarshalJSON() ([]byte, error) {
+	return marshalEntity(iv)
+}
+
+func (iv *IdentificacaoVotavel) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, iv)
+}
+
+func (mz MunicipioZona) MarshalJSON() ([]byte, error) {
+	return marshalEntity(mz)
+}
+
+func (mz *MunicipioZona) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, mz)
+}
+
+func (r ResultadoVotacao) MarshalJSON() ([]byte, error) {
+	return marshalEntity(r)
+}
+
+func (r *ResultadoVotacao) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, r)
+}
+
+func (r ResultadoVotacaoPorEleicao) MarshalJSON() ([]byte, error) {
+	return marshalEntity(r)
+}
+
+func (r *ResultadoVotacaoPorEleicao) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, r)
+}
+
+func (vc TotalVotosCargo) MarshalJSON() ([]byte, error) {
+	return marshalEntity(vc)
+}
+
+func (vc *TotalVotosCargo) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, vc)
+}
+
+func (vv TotalVotosVotavel) MarshalJSON() ([]byte, error) {
+	return marshalEntity(vv)
+}
+
+func (vv *TotalVotosVotavel) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, vv)
+}
+
+func (e EntidadeResultadoRDV) MarshalJSON() ([]byte, error) {
+	return marshalEntity(e)
+}
+
+func (e *EntidadeResultadoRDV) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, e)
+}
+
+func (rdv EntidadeRegistroDigitalVoto) MarshalJSON() ([]byte, error) {
+	return marshalEntity(rdv)
+}
+
+func (rdv *EntidadeRegistroDigitalVoto) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, rdv)
+}
+
+func (e EleicaoVota) MarshalJSON() ([]byte, error) {
+	return marshalEntity(e)
+}
+
+func (e *EleicaoVota) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, e)
+}
+
+func (e EleicaoSA) MarshalJSON() ([]byte, error) {
+	return marshalEntity(e)
+}
+
+func (e *EleicaoSA) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, e)
+}
+
+func (v Voto) MarshalJSON() ([]byte, error) {
+	return marshalEntity(v)
+}
+
+func (v *Voto) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, v)
+}
+
+func (vc VotosCargo) MarshalJSON() ([]byte, error) {
+	return marshalEntity(vc)
+}
+
+func (vc *VotosCargo) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, vc)
+}
+
+func (sig EntidadeAssinatura) MarshalJSON() ([]byte, error) {
+	return marshalEntity(sig)
+}
+
+func (sig *EntidadeAssinatura) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, sig)
+}
+
+func (e EntidadeAssinaturaResultado) MarshalJSON() ([]byte, error) {
+	return marshalEntity(e)
+}
+
+func (e *EntidadeAssinaturaResultado) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, e)
+}
+
+func (a AlgoritmoAssinaturaInfo) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *AlgoritmoAssinaturaInfo) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a AlgoritmoHashInfo) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *AlgoritmoHashInfo) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a Assinatura) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *Assinatura) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a AssinaturaArquivo) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *AssinaturaArquivo) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a AssinaturaDigital) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *AssinaturaDigital) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (a AutoAssinaturaDigital) MarshalJSON() ([]byte, error) {
+	return marshalEntity(a)
+}
+
+func (a *AutoAssinaturaDigital) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, a)
+}
+
+func (d DescritorChave) MarshalJSON() ([]byte, error) {
+	return marshalEntity(d)
+}
+
+func (d *DescritorChave) UnmarshalJSON(data []byte) error {
+	return unmarshalEntity(data, d)
+}
+
+// How a CHOICE variant is encoded in the contents of its tag.
+type choiceEncoding int
+
+const (
+	choiceSequence   choiceEncoding = iota // IMPLICIT SEQUENCE: the TLVs of the fields.
+	choiceSequenceOf                       // IMPLICIT SEQUENCE OF: the TLVs of the items.
+	choiceImplicit                         // IMPLICIT INTEGER or ENUMERATED.
+	choiceExplicit                         // EXPLICIT: the TLV of the value.
+)
+
+type choiceVariant struct {
+	tag      int
+	value    any // Zero value of the variant's type; JSON names it after the type.
+	encoding choiceEncoding
+}
+
+var identificacaoUrnaVariants = []choiceVariant{
+	{0, IdentificacaoSecaoEleitoral{}, choiceSequence},
+	{1, IdentificacaoContingencia{}, choiceSequence},
+	{2, IdentificacaoMesaJustificativa{}, choiceSequence},
+}
+
+var codigoCargoVariants = []choiceVariant{
+	{1, CargoConstitucional(0), choiceImplicit},
+	{2, NumeroCargoConsultaLivre(0), choiceExplicit},
+}
+
+// Variants of the CHOICE fields, mirroring their `Read<Field>` methods.
+var jsonChoices = map[string][]choiceVariant{
+	"CabecalhoEntidade.IdEleitoral": {
+		{1, IDProcessoEleitoral(0), choiceImplicit},
+		{2, IDPleito(0), choiceImplicit},
+		{3, IDEleicao(0), choiceImplicit},
+	},
+	"Urna.MotivoUtilizacaoSA": {
+		{0, ApuracaoMistaMR{}, choiceSequence},
+		{1, ApuracaoMistaBUAE{}, choiceSequence},
+		{2, ApuracaoTotalmenteManualDigitacaoAE{}, choiceSequence},
+		{3, ApuracaoEletronica{}, choiceSequence},
+	},
+	"EntidadeEnvelopeGenerico.Identificacao": identificacaoUrnaVariants,
+	"CorrespondenciaResultado.Identificacao": identificacaoUrnaVariants,
+	"EntidadeBoletimUrna.DadosSecaoSA": {
+		{0, DadosSecao{}, choiceSequence},
+		{1, DadosSA{}, choiceSequence},
+	},
+	"TotalVotosCargo.CodigoCargo": codigoCargoVariants,
+	"VotosCargo.IdCargo":          codigoCargoVariants,
+	"EntidadeRegistroDigitalVoto.Eleicoes": {
+		{0, []EleicaoVota{}, choiceSequenceOf},
+		{1, []EleicaoSA{}, choiceSequenceOf},
+	},
+}
+
+func marshalEntity(v any) ([]byte, error) {
+	return json.Marshal(inspectStruct(reflect.ValueOf(v), true))
+}
+
+func unmarshalEntity(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var j any
+	err := dec.Decode(&j)
+	if err != nil {
+		return err
+	}
+
+	e := reflect.ValueOf(v).Elem()
+	return decodeJSON(j, e, e.Type().Name())
+}
+
+// Sets `v` from the decoded JSON `j`; `key` is the "Type.Field" of `v`.
+func decodeJSON(j any, v reflect.Value, key string) error {
+	switch v.Interface().(type) {
+	case asn1.Enumerated:
+		e, err := decodeEnumerated(j, key)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(e))
+		return nil
+	case asn1.RawValue:
+		raw, err := decodeChoice(j, key)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(raw))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		o, ok := j.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected object", key)
+		}
+
+		for name, fj := range o {
+			field, ok := v.Type().FieldByName(name)
+			if !ok || !field.IsExported() {
+				return fmt.Errorf("%s: unknown field %s", v.Type().Name(), name)
+			}
+
+			err := decodeJSON(fj, v.FieldByIndex(field.Index), v.Type().Name()+"."+name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			s, ok := j.(string)
+			if !ok {
+				return fmt.Errorf("%s: expected hex string", key)
+			}
+
+			b, err := hex.DecodeString(s)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			v.SetBytes(b)
+			return nil
+		}
+
+		a, ok := j.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected array", key)
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(a), len(a))
+		for i := range a {
+			err := decodeJSON(a[i], s.Index(i), key)
+			if err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.String:
+		s, ok := j.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string", key)
+		}
+		v.SetString(encodeLatin1(s))
+		return nil
+	case reflect.Bool:
+		b, ok := j.(bool)
+		if !ok {
+			return fmt.Errorf("%s: expected boolean", key)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return decodeNumber(j, v, key)
+	}
+
+	return fmt.Errorf("%s: unsupported type %s", key, v.Type())
+}
+
+// Numbers, or names of types with a String method (e.g. CargoConstitucional).
+func decodeNumber(j any, v reflect.Value, key string) error {
+	var n int64
+	switch j := j.(type) {
+	case json.Number:
+		var err error
+		n, err = j.Int64()
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	case string:
+		var ok bool
+		n, ok = stringerValue(v.Type(), j)
+		if !ok {
+			return fmt.Errorf("%s: unknown value %q", key, j)
+		}
+	default:
+		return fmt.Errorf("%s: expected number", key)
+	}
+
+	if v.CanInt() {
+		v.SetInt(n)
+	} else {
+		v.SetUint(uint64(n))
+	}
+
+	return nil
+}
+
+func stringerValue(t reflect.Type, name string) (int64, bool) {
+	for n := int64(1); n <= 0xff; n++ {
+		s, ok := reflect.ValueOf(n).Convert(t).Interface().(fmt.Stringer)
+		if !ok {
+			return 0, false
+		}
+		if s.String() == name {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+func decodeEnumerated(j any, key string) (asn1.Enumerated, error) {
+	switch j := j.(type) {
+	case json.Number:
+		n, err := j.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", key, err)
+		}
+		return asn1.Enumerated(n), nil
+	case string:
+		enum, ok := inspectEnum(key)
+		if ok {
+			e, ok := enumValue(enum, j)
+			if ok {
+				return e, nil
+			}
+		}
+		return 0, fmt.Errorf("%s: unknown value %q", key, j)
+	}
+
+	return 0, fmt.Errorf("%s: expected number or name", key)
+}
+
+// Decodes `{"<Variant>": value}` with the variants of `key`, or a raw TLV.
+func decodeChoice(j any, key string) (asn1.RawValue, error) {
+	o, ok := j.(map[string]any)
+	if !ok {
+		return asn1.RawValue{}, fmt.Errorf("%s: expected object", key)
+	}
+	if _, ok := o["tag"]; ok {
+		return decodeRaw(o, key)
+	}
+	if len(o) != 1 {
+		return asn1.RawValue{}, fmt.Errorf("%s: expected a single variant", key)
+	}
+
+	for name, vj := range o {
+		for _, c := range jsonChoices[key] {
+			if choiceName(reflect.ValueOf(c.value)) != name {
+				continue
+			}
+
+			v := reflect.New(reflect.TypeOf(c.value)).Elem()
+			err := decodeJSON(vj, v, key)
+			if err != nil {
+				return asn1.RawValue{}, err
+			}
+
+			raw, err := c.encode(v)
+			if err != nil {
+				return asn1.RawValue{}, fmt.Errorf("%s: %w", key, err)
+			}
+			return raw, nil
+		}
+
+		return asn1.RawValue{}, fmt.Errorf("%s: unknown variant %s", key, name)
+	}
+
+	return asn1.RawValue{}, nil
+}
+
+func (c choiceVariant) encode(v reflect.Value) (asn1.RawValue, error) {
+	raw := asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: c.tag, IsCompound: c.encoding != choiceImplicit}
+
+	switch c.encoding {
+	case choiceSequence:
+		b, err := marshalFields(v)
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		raw.Bytes = b
+	case choiceSequenceOf:
+		for i := 0; i < v.Len(); i++ {
+			b, err := marshalDER(v.Index(i), "")
+			if err != nil {
+				return asn1.RawValue{}, err
+			}
+			raw.Bytes = append(raw.Bytes, b...)
+		}
+	case choiceImplicit:
+		n := v.Convert(reflect.TypeOf(int64(0))).Interface()
+		b, err := asn1.Marshal(n)
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+
+		var i asn1.RawValue
+		_, err = asn1.Unmarshal(b, &i)
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		raw.Bytes = i.Bytes
+	case choiceExplicit:
+		b, err := marshalDER(v, "")
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		raw.Bytes = b
+	}
+
+	return withFullBytes(raw)
+}
+
+// DER of `v` as in the TSE files: strings are GeneralStrings unless `params`
+// names another string type, which asn1.Marshal cannot do by itself.
+func marshalDER(v reflect.Value, params string) ([]byte, error) {
+	switch v.Interface().(type) {
+	case asn1.RawValue, asn1.Enumerated, []byte:
+		return asn1.MarshalWithParams(v.Interface(), params)
+	}
+
+	var raw asn1.RawValue
+	switch v.Kind() {
+	case reflect.String:
+		for _, p := range strings.Split(params, ",") {
+			switch p {
+			case "ia5", "printable", "numeric", "utf8":
+				return asn1.MarshalWithParams(v.Interface(), params)
+			}
+		}
+		raw = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagGeneralString, Bytes: []byte(v.String())}
+	case reflect.Struct:
+		b, err := marshalFields(v)
+		if err != nil {
+			return nil, err
+		}
+		raw = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: b}
+	case reflect.Slice:
+		raw = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true}
+		for i := 0; i < v.Len(); i++ {
+			b, err := marshalDER(v.Index(i), "")
+			if err != nil {
+				return nil, err
+			}
+			raw.Bytes = append(raw.Bytes, b...)
+		}
+	default:
+		return asn1.MarshalWithParams(v.Interface(), params)
+	}
+
+	tag, explicit, err := fieldTag(params)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case tag < 0:
+	case explicit:
+		inner, err := asn1.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		raw = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: true, Bytes: inner}
+	default:
+		raw.Class, raw.Tag = asn1.ClassContextSpecific, tag
+	}
+
+	return asn1.Marshal(raw)
+}
+
+// The `tag:<n>` (-1 if none) and `explicit` options of an `asn1` struct tag.
+func fieldTag(params string) (int, bool, error) {
+	tag, explicit := -1, false
+	for _, p := range strings.Split(params, ",") {
+		switch {
+		case p == "explicit":
+			explicit = true
+		case strings.HasPrefix(p, "tag:"):
+			var err error
+			tag, err = strconv.Atoi(p[4:])
+			if err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	return tag, explicit, nil
+}
+
+// Contents of a SEQUENCE: its fields, without the zero optional ones.
+func marshalFields(v reflect.Value) ([]byte, error) {
+	var b []byte
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		params := field.Tag.Get("asn1")
+		f := v.Field(i)
+		if strings.Contains(params, "optional") && f.IsZero() {
+			continue
+		}
+
+		fb, err := marshalDER(f, params)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, fb...)
+	}
+
+	return b, nil
+}
+
+// Rebuilds a TLV shown by inspectRaw.
+func decodeRaw(o map[string]any, key string) (asn1.RawValue, error) {
+	var raw asn1.RawValue
+	for name, p := range map[string]*int{"tag": &raw.Tag, "class": &raw.Class} {
+		n, ok := o[name].(json.Number)
+		if !ok {
+			return asn1.RawValue{}, fmt.Errorf("%s: expected %s number", key, name)
+		}
+
+		i, err := n.Int64()
+		if err != nil {
+			return asn1.RawValue{}, fmt.Errorf("%s: %w", key, err)
+		}
+		*p = int(i)
+	}
+
+	if children, ok := o["children"].([]any); ok {
+		raw.IsCompound = true
+		for _, c := range children {
+			co, ok := c.(map[string]any)
+			if !ok {
+				return asn1.RawValue{}, fmt.Errorf("%s: expected object", key)
+			}
+
+			if _, ok := co["tag"]; !ok {
+				// Bytes that were not a TLV.
+				b, err := decodeHex(co["hex"], key)
+				if err != nil {
+					return asn1.RawValue{}, err
+				}
+				raw.Bytes = append(raw.Bytes, b...)
+				continue
+			}
+
+			child, err := decodeRaw(co, key)
+			if err != nil {
+				return asn1.RawValue{}, err
+			}
+			raw.Bytes = append(raw.Bytes, child.FullBytes...)
+		}
+	} else {
+		b, err := decodeHex(o["hex"], key)
+		if err != nil {
+			return asn1.RawValue{}, err
+		}
+		raw.Bytes = b
+	}
+
+	return withFullBytes(raw)
+}
+
+func decodeHex(j any, key string) ([]byte, error) {
+	s, ok := j.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected hex string", key)
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return b, nil
+}
+
+func withFullBytes(raw asn1.RawValue) (asn1.RawValue, error) {
+	b, err := asn1.Marshal(raw)
+	if err != nil {
+		return asn1.RawValue{}, err
+	}
+
+	raw.FullBytes = b
+	return raw, nil
+}
+
+// Non-ASCII text goes back to ISO-8859-1, the encoding of the GeneralStrings.
+func encodeLatin1(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			e, err := charmap.ISO8859_1.NewEncoder().String(s)
+			if err != nil {
+				return s
+			}
+			return e
+		}
+	}
+
+	return s
+}
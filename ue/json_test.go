@@ -0,0 +1,105 @@
+package ue
+
+import (
+	"encoding/json"
+	"github.com/google/certificate-transparency-go/asn1"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		path   string
+		entity any
+	}{
+		{"test-data/urna.bu", &EntidadeEnvelopeGenerico{}},
+		{"test-data/urna.rdv", &EntidadeResultadoRDV{}},
+		{"test-data/urna.vscmr", &EntidadeAssinaturaResultado{}},
+	}
+
+	for _, tt := range tests {
+		data, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = asn1.Unmarshal(data, tt.entity)
+		if err != nil {
+			t.Fatal(tt.path, err)
+		}
+
+		j, err := json.Marshal(tt.entity)
+		if err != nil {
+			t.Fatal(tt.path, err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(tt.entity).Elem()).Interface()
+		err = json.Unmarshal(j, decoded)
+		if err != nil {
+			t.Fatal(tt.path, err)
+		}
+
+		if !reflect.DeepEqual(decoded, tt.entity) {
+			t.Errorf("%s: decoded entity differs\n%+v\n%+v", tt.path, decoded, tt.entity)
+		}
+
+		again, err := json.Marshal(decoded)
+		if err != nil {
+			t.Fatal(tt.path, err)
+		}
+		if string(j) != string(again) {
+			t.Errorf("%s: round trip differs\n%s\n%s", tt.path, j, again)
+		}
+	}
+}
+
+func TestJSONBu(t *testing.T) {
+	var e EntidadeEnvelopeGenerico
+	data, err := os.ReadFile("test-data/urna.bu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = asn1.Unmarshal(data, &e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bu, err := e.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := json.Marshal(bu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"Fase":"Oficial"`, `"IdEleitoral":{"IDPleito":406}`, `"DadosSecaoSA":{"DadosSecao":`, `"TipoVoto":"Nominal"`} {
+		if !strings.Contains(string(j), s) {
+			t.Error("expected", s)
+		}
+	}
+
+	var decoded EntidadeBoletimUrna
+	err = json.Unmarshal(j, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p, ok := decoded.Pleito(); !ok || p != 406 {
+		t.Error("wrong pleito", p)
+	}
+	if !reflect.DeepEqual(decoded, bu) {
+		t.Errorf("decoded BU differs\n%+v\n%+v", decoded, bu)
+	}
+	if !reflect.DeepEqual(ListVotosBu(bu), ListVotosBu(decoded)) {
+		t.Error("votes differ after round trip")
+	}
+	if _, err := decoded.ReadDadosSecaoSA(); err != nil {
+		t.Error(err)
+	}
+
+	err = json.Unmarshal([]byte(`{"Fase":"Desconhecida"}`), &decoded)
+	if err == nil {
+		t.Error("expected unknown enum error")
+	}
+}
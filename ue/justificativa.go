@@ -0,0 +1,93 @@
+package ue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/certificate-transparency-go/asn1"
+)
+
+var ErrMesaJustificativa = errors.New("envelope of a mesa receptora de justificativa")
+
+// Urna identified by an envelope: a seção (or contingência) or a mesa
+// receptora de justificativa.
+type UrnaEnvelope struct {
+	Filename      string
+	TipoEnvelope  TipoEnvelope
+	Identificacao IdentificacaoUrna
+	Municipio     Municipio // In the reference data of the pleito of the envelope.
+}
+
+func (u UrnaEnvelope) IsMesaJustificativa() bool {
+	_, ok := u.Identificacao.(IdentificacaoMesaJustificativa)
+	return ok
+}
+
+// Whether the envelope comes from a mesa receptora de justificativa.
+func (eeg EntidadeEnvelopeGenerico) IsMesaJustificativa() bool {
+	i, err := eeg.ReadIdentificacao()
+	_, ok := i.(IdentificacaoMesaJustificativa)
+	return err == nil && ok
+}
+
+func newUrnaEnvelope(filename string, eeg EntidadeEnvelopeGenerico) (UrnaEnvelope, error) {
+	i, err := eeg.ReadIdentificacao()
+	if err != nil {
+		return UrnaEnvelope{}, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	id := int(i.GetMunicipioZona().Municipio)
+	var m Municipio
+	if p, ok := eeg.Cabecalho.Pleito(); ok {
+		m, _ = MunicipioFromIdPleito(id, p)
+	} else {
+		m, _ = MunicipioFromId(id)
+	}
+
+	return UrnaEnvelope{
+		Filename:      filename,
+		TipoEnvelope:  TipoEnvelope(eeg.TipoEnvelope),
+		Identificacao: i,
+		Municipio:     m,
+	}, nil
+}
+
+// Identifies the urna of a `*.bu` file, whatever its envelope type.
+func ReadUrnaEnvelope(path string) (UrnaEnvelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return UrnaEnvelope{}, err
+	}
+
+	var eeg EntidadeEnvelopeGenerico
+	_, err = asn1.Unmarshal(data, &eeg)
+	if err != nil {
+		return UrnaEnvelope{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return newUrnaEnvelope(path, eeg)
+}
+
+// Identifies the urnas of the `*.bu` files in a section zip. Files that
+// cannot be decoded are skipped; the first error is returned.
+func ListUrnasZip(path string) ([]UrnaEnvelope, error) {
+	var urnas []UrnaEnvelope
+	var readErr error
+	err := ProcessZip(path, func(eeg EntidadeEnvelopeGenerico, ctx ZipProcessCtx) {
+		u, err := newUrnaEnvelope(ctx.Filename, eeg)
+		if err != nil {
+			if readErr == nil {
+				readErr = err
+			}
+			return
+		}
+
+		urnas = append(urnas, u)
+	})
+	if err != nil {
+		return urnas, err
+	}
+
+	return urnas, readErr
+}
@@ -0,0 +1,67 @@
+package ue
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/certificate-transparency-go/asn1"
+)
+
+// Envelope of a mesa receptora de justificativa, as no test file has one.
+func justificativaEnvelope(t *testing.T) []byte {
+	mesa, err := asn1.Marshal(IdentificacaoMesaJustificativa{MunicipioZona{1007, 9}, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seq asn1.RawValue
+	_, err = asn1.Unmarshal(mesa, &seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pleito, err := asn1.MarshalWithParams(407, "tag:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := asn1.Marshal(EntidadeEnvelopeGenerico{
+		Cabecalho:     CabecalhoEntidade{DataGeracao: "20221030T170000", IdEleitoral: asn1.RawValue{FullBytes: pleito}},
+		Fase:          3,
+		Identificacao: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true, Bytes: seq.Bytes},
+		TipoEnvelope:  asn1.Enumerated(EnvelopeImagemBiometria),
+		Conteudo:      []byte{0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestMesaJustificativa(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "justificativa.bu")
+	err := os.WriteFile(path, justificativaEnvelope(t), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := ReadUrnaEnvelope(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.IsMesaJustificativa() || u.Municipio.Nome != "BUJARI" {
+		t.Error("unexpected urna", u)
+	}
+
+	_, err = BuEntry{path}.ReadBu()
+	if !errors.Is(err, ErrMesaJustificativa) {
+		t.Error("expected ErrMesaJustificativa, got", err)
+	}
+
+	urnas, err := ListUrnasZip("test-data/o00407-0100700090001.zip")
+	if err != nil || len(urnas) != 1 || urnas[0].IsMesaJustificativa() {
+		t.Error("expected one urna de seção, got", urnas, err)
+	}
+}
@@ -0,0 +1,202 @@
+package ue
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Local de votação; Latitude and Longitude are both zero when unknown.
+type LocalVotacao struct {
+	Municipio CodigoMunicipio `json:"municipio"`
+	Zona      NumeroZona      `json:"zona"`
+	Local     NumeroLocal     `json:"local"`
+	Nome      string          `json:"nome,omitempty"`
+	Endereco  string          `json:"endereco,omitempty"`
+	Bairro    string          `json:"bairro,omitempty"`
+	Latitude  float64         `json:"latitude,omitempty"`
+	Longitude float64         `json:"longitude,omitempty"`
+}
+
+func (l LocalVotacao) HasCoordenadas() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+type chaveLocal struct {
+	municipio CodigoMunicipio
+	zona      NumeroZona
+	numero    int // Local or seção.
+}
+
+// Locais de votação indexed by município, zona and local, and by seção when
+// the file lists them.
+type RegistroLocais struct {
+	locais   []LocalVotacao
+	porLocal map[chaveLocal]int
+	porSecao map[chaveLocal]int
+}
+
+var colunasLocal = []string{"municipio", "zona", "local", "secao", "nome", "endereco", "bairro", "latitude", "longitude"}
+
+// Reads a CSV (comma or semicolon separated) whose header names the columns
+// `municipio`, `zona` and `local`, and optionally `secao`, `nome`, `endereco`,
+// `bairro`, `latitude` and `longitude`, in any order; other columns are
+// ignored. With a `secao` column, a local may repeat once per seção.
+func NewRegistroLocais(r io.Reader) (*RegistroLocais, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(strings.NewReader(string(data)))
+	cr.FieldsPerRecord = -1
+	header, _, _ := strings.Cut(string(data), "\n")
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		cr.Comma = ';'
+	}
+
+	record, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+
+	col := make(map[string]int)
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for _, c := range colunasLocal {
+			if name == c {
+				col[c] = i
+			}
+		}
+	}
+	for _, c := range colunasLocal[:3] {
+		if _, ok := col[c]; !ok {
+			return nil, fmt.Errorf("header: missing column %s", c)
+		}
+	}
+
+	reg := &RegistroLocais{
+		porLocal: make(map[chaveLocal]int),
+		porSecao: make(map[chaveLocal]int),
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		field := func(c string) string {
+			i, ok := col[c]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		var n [4]int
+		for i, c := range colunasLocal[:4] {
+			if c == "secao" && len(field(c)) == 0 {
+				continue
+			}
+			n[i], err = strconv.Atoi(field(c))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, c, err)
+			}
+		}
+
+		l := LocalVotacao{
+			Municipio: CodigoMunicipio(n[0]),
+			Zona:      NumeroZona(n[1]),
+			Local:     NumeroLocal(n[2]),
+			Nome:      field("nome"),
+			Endereco:  field("endereco"),
+			Bairro:    field("bairro"),
+		}
+		for c, v := range map[string]*float64{"latitude": &l.Latitude, "longitude": &l.Longitude} {
+			if len(field(c)) == 0 {
+				continue
+			}
+			*v, err = strconv.ParseFloat(strings.Replace(field(c), ",", ".", 1), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, c, err)
+			}
+		}
+
+		reg.add(l, NumeroSecao(n[3]))
+	}
+
+	return reg, nil
+}
+
+func (reg *RegistroLocais) add(l LocalVotacao, secao NumeroSecao) {
+	k := chaveLocal{l.Municipio, l.Zona, int(l.Local)}
+	i, ok := reg.porLocal[k]
+	if !ok {
+		i = len(reg.locais)
+		reg.locais = append(reg.locais, l)
+		reg.porLocal[k] = i
+	}
+
+	if secao != 0 {
+		reg.porSecao[chaveLocal{l.Municipio, l.Zona, int(secao)}] = i
+	}
+}
+
+// Reads a file in the format of NewRegistroLocais.
+func ReadRegistroLocais(path string) (*RegistroLocais, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reg, err := NewRegistroLocais(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return reg, nil
+}
+
+func (reg *RegistroLocais) Find(m CodigoMunicipio, z NumeroZona, l NumeroLocal) (LocalVotacao, bool) {
+	i, ok := reg.porLocal[chaveLocal{m, z, int(l)}]
+	if !ok {
+		return LocalVotacao{}, false
+	}
+
+	return reg.locais[i], true
+}
+
+// Finds the local of a seção by its local number or, failing that, by the
+// seção (only if the file lists seções).
+func (reg *RegistroLocais) ForSecao(id IdentificacaoSecaoEleitoral) (LocalVotacao, bool) {
+	m, z := id.MunicipioZona.Municipio, id.MunicipioZona.Zona
+	l, ok := reg.Find(m, z, id.Local)
+	if ok {
+		return l, true
+	}
+
+	return reg.FindSecao(m, z, id.Secao)
+}
+
+// Finds the local of a seção; only works if the file lists seções.
+func (reg *RegistroLocais) FindSecao(m CodigoMunicipio, z NumeroZona, s NumeroSecao) (LocalVotacao, bool) {
+	i, ok := reg.porSecao[chaveLocal{m, z, int(s)}]
+	if !ok {
+		return LocalVotacao{}, false
+	}
+
+	return reg.locais[i], true
+}
+
+// Lists all locais in file order.
+func (reg *RegistroLocais) All() []LocalVotacao {
+	return append([]LocalVotacao{}, reg.locais...)
+}
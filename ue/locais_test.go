@@ -0,0 +1,48 @@
+package ue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistroLocais(t *testing.T) {
+	csv := "zona;municipio;local;secao;nome;endereco;bairro;latitude;longitude\n" +
+		"9;1007;1023;1;ESCOLA A;RUA 1;CENTRO;-9,8;-67,9\n" +
+		"9;1007;1023;2;ESCOLA A;RUA 1;CENTRO;-9,8;-67,9\n" +
+		"9;1007;1031;;ESCOLA B;;;;\n"
+	reg, err := NewRegistroLocais(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(reg.All()); n != 2 {
+		t.Error("expected 2 locais, got", n)
+	}
+
+	l, ok := reg.Find(1007, 9, 1023)
+	if !ok || l.Nome != "ESCOLA A" || l.Latitude != -9.8 || !l.HasCoordenadas() {
+		t.Error("unexpected local", l)
+	}
+
+	l, ok = reg.FindSecao(1007, 9, 2)
+	if !ok || l.Local != 1023 {
+		t.Error("expected local by seção, got", l)
+	}
+
+	id := IdentificacaoSecaoEleitoral{MunicipioZona: MunicipioZona{Municipio: 1007, Zona: 9}, Local: 1031, Secao: 5}
+	l, ok = reg.ForSecao(id)
+	if !ok || l.Nome != "ESCOLA B" || l.HasCoordenadas() {
+		t.Error("unexpected local", l)
+	}
+
+	id.Local, id.Secao = 9999, 2
+	l, ok = reg.ForSecao(id)
+	if !ok || l.Local != 1023 {
+		t.Error("expected fallback to seção, got", l)
+	}
+
+	_, err = NewRegistroLocais(strings.NewReader("municipio,zona,nome\n1007,9,X\n"))
+	if err == nil {
+		t.Error("expected error for missing local column")
+	}
+}
@@ -6,61 +6,275 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Urna times are converted on hosts without a zoneinfo database.
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
-//go:embed resource/municipios.csv
+// Reference packs of the elections (see LoadReferencias): TSE and IBGE codes
+// of the municipios, pleitos and cargos. The IBGE codes are filled from the
+// TSE/IBGE correspondence file with `go run ./internal/ibge <file>`.
+//
+//go:embed resource
 var f embed.FS
 
 type Municipio struct {
-	Id   int
+	Id   int // Código TSE.
 	Nome string
 	Uf   string
+	Ibge int // Código IBGE; zero when unknown.
 }
 
-var cache map[int]Municipio = make(map[int]Municipio)
-
 func (m Municipio) String() string {
 	return fmt.Sprintf("%s (%s)", m.Nome, m.Uf)
 }
 
-func MunicipioFromId(id int) (Municipio, error) {
-	m, ok := cache[id]
-	if ok {
-		return m, nil
+// Time zones of the UFs not on Brasília time (America/Sao_Paulo), or with a
+// different history of daylight saving time.
+var fusosUf = map[string]string{
+	"AC": "America/Rio_Branco",
+	"AM": "America/Manaus",
+	"RR": "America/Boa_Vista",
+	"RO": "America/Porto_Velho",
+	"MT": "America/Cuiaba",
+	"MS": "America/Campo_Grande",
+	"PA": "America/Belem",
+	"AP": "America/Belem",
+	"TO": "America/Araguaina",
+	"MA": "America/Fortaleza",
+	"PI": "America/Fortaleza",
+	"CE": "America/Fortaleza",
+	"RN": "America/Fortaleza",
+	"PB": "America/Fortaleza",
+	"PE": "America/Recife",
+	"AL": "America/Maceio",
+	"SE": "America/Maceio",
+	"BA": "America/Bahia",
+}
+
+var fusos sync.Map
+
+// Time zone of the urna clocks in the município: the legal time of its UF,
+// or Brasília time when the UF is unknown.
+func (m Municipio) Location() *time.Location {
+	fuso, ok := fusosUf[m.Uf]
+	if !ok {
+		fuso = "America/Sao_Paulo"
+	}
+
+	if loc, ok := fusos.Load(fuso); ok {
+		return loc.(*time.Location)
 	}
 
-	municipios, err := f.Open("resource/municipios.csv")
+	loc, err := time.LoadLocation(fuso)
 	if err != nil {
-		log.Println(err)
-		return Municipio{id, "?", "?"}, errors.New("could not process csv")
+		return time.UTC
+	}
+	fusos.Store(fuso, loc)
+
+	return loc
+}
+
+// Municípios indexed by TSE code, IBGE code, name and UF.
+type RegistroMunicipios struct {
+	municipios []Municipio
+	porId      map[int]int
+	porIbge    map[int]int
+	porNome    map[string][]int
+	porUf      map[string][]int
+}
+
+// Reads a CSV of `codigo TSE,nome,UF[,codigo IBGE]` records.
+func NewRegistroMunicipios(r io.Reader) (*RegistroMunicipios, error) {
+	reg := &RegistroMunicipios{
+		porId:   make(map[int]int),
+		porIbge: make(map[int]int),
+		porNome: make(map[string][]int),
+		porUf:   make(map[string][]int),
 	}
-	defer municipios.Close()
 
-	r := csv.NewReader(municipios)
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
 	for {
-		record, err := r.Read()
+		record, err := cr.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			log.Println(err)
-			return Municipio{id, "?", "?"}, errors.New("could not process csv")
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("line %d: expected 3 or 4 fields, got %d", line, len(record))
 		}
 
-		i, err := strconv.Atoi(record[0])
+		id, err := strconv.Atoi(record[0])
 		if err != nil {
-			log.Println(err)
-			return Municipio{id, "?", "?"}, errors.New("could not process csv")
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		m := Municipio{Id: id, Nome: record[1], Uf: strings.ToUpper(record[2])}
+		if len(record) == 4 && len(record[3]) > 0 {
+			m.Ibge, err = strconv.Atoi(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
 		}
 
-		if i == id {
-			mun := Municipio{id, record[1], record[2]}
-			cache[id] = mun
-			return mun, nil
+		reg.add(m)
+	}
+
+	return reg, nil
+}
+
+func (reg *RegistroMunicipios) add(m Municipio) {
+	i := len(reg.municipios)
+	reg.municipios = append(reg.municipios, m)
+
+	reg.porId[m.Id] = i
+	if m.Ibge != 0 {
+		reg.porIbge[m.Ibge] = i
+	}
+	nome := NormalizeNome(m.Nome)
+	reg.porNome[nome] = append(reg.porNome[nome], i)
+	reg.porUf[m.Uf] = append(reg.porUf[m.Uf], i)
+}
+
+func (reg *RegistroMunicipios) ById(id int) (Municipio, bool) {
+	i, ok := reg.porId[id]
+	if !ok {
+		return Municipio{}, false
+	}
+
+	return reg.municipios[i], true
+}
+
+func (reg *RegistroMunicipios) ByIbge(ibge int) (Municipio, bool) {
+	i, ok := reg.porIbge[ibge]
+	if !ok {
+		return Municipio{}, false
+	}
+
+	return reg.municipios[i], true
+}
+
+// Finds municípios by name ignoring case and accents; an empty `uf` matches
+// every UF, as names repeat across UFs.
+func (reg *RegistroMunicipios) ByNome(nome, uf string) []Municipio {
+	var municipios []Municipio
+	for _, i := range reg.porNome[NormalizeNome(nome)] {
+		m := reg.municipios[i]
+		if len(uf) == 0 || strings.EqualFold(uf, m.Uf) {
+			municipios = append(municipios, m)
 		}
 	}
 
-	return Municipio{id, "?", "?"}, fmt.Errorf("could not find for id=%d", id)
+	return municipios
+}
+
+// Lists the municípios of a UF in file order.
+func (reg *RegistroMunicipios) ByUf(uf string) []Municipio {
+	var municipios []Municipio
+	for _, i := range reg.porUf[strings.ToUpper(uf)] {
+		municipios = append(municipios, reg.municipios[i])
+	}
+
+	return municipios
+}
+
+// Lists all municípios in file order.
+func (reg *RegistroMunicipios) All() []Municipio {
+	return append([]Municipio{}, reg.municipios...)
+}
+
+// Registry of the default reference (see ReferenciaFor).
+func Municipios() (*RegistroMunicipios, error) {
+	r, err := referenciaPadrao()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Municipios, nil
+}
+
+func MunicipioFromId(id int) (Municipio, error) {
+	reg, err := Municipios()
+	if err != nil {
+		return Municipio{Id: id, Nome: "?", Uf: "?"}, fmt.Errorf("could not process csv: %w", err)
+	}
+
+	return municipioFromId(reg, id)
+}
+
+// Looks the município up in the reference of `pleito`.
+func MunicipioFromIdPleito(id int, pleito IDPleito) (Municipio, error) {
+	r, err := ReferenciaFor(pleito)
+	if err != nil {
+		return Municipio{Id: id, Nome: "?", Uf: "?"}, fmt.Errorf("could not process csv: %w", err)
+	}
+
+	return municipioFromId(r.Municipios, id)
+}
+
+func municipioFromId(reg *RegistroMunicipios, id int) (Municipio, error) {
+	m, ok := reg.ById(id)
+	if !ok {
+		return Municipio{Id: id, Nome: "?", Uf: "?"}, fmt.Errorf("could not find for id=%d", id)
+	}
+
+	return m, nil
+}
+
+func MunicipioFromIbge(ibge int) (Municipio, error) {
+	reg, err := Municipios()
+	if err != nil {
+		return Municipio{}, fmt.Errorf("could not process csv: %w", err)
+	}
+
+	m, ok := reg.ByIbge(ibge)
+	if !ok {
+		return Municipio{}, fmt.Errorf("could not find for ibge=%d", ibge)
+	}
+
+	return m, nil
+}
+
+var ErrAmbiguousMunicipio = errors.New("ambiguous município name, give the UF")
+
+// Finds a município by name (ignoring case and accents) and UF; without UF,
+// the name must be unique.
+func MunicipioFromNome(nome, uf string) (Municipio, error) {
+	reg, err := Municipios()
+	if err != nil {
+		return Municipio{}, fmt.Errorf("could not process csv: %w", err)
+	}
+
+	ms := reg.ByNome(nome, uf)
+	switch len(ms) {
+	case 0:
+		return Municipio{}, fmt.Errorf("could not find for nome=%s uf=%s", nome, uf)
+	case 1:
+		return ms[0], nil
+	}
+
+	return Municipio{}, fmt.Errorf("%s: %w", nome, ErrAmbiguousMunicipio)
+}
+
+// Upper case without accents, for comparing names.
+func NormalizeNome(nome string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, err := transform.String(t, nome)
+	if err != nil {
+		s = nome
+	}
+
+	return strings.ToUpper(strings.TrimSpace(s))
 }
@@ -0,0 +1,112 @@
+package ue
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMunicipioFromId(t *testing.T) {
+	m, err := MunicipioFromId(1007)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Nome != "BUJARI" || m.Uf != "AC" {
+		t.Error("unexpected municipio", m)
+	}
+
+	_, err = MunicipioFromId(-1)
+	if err == nil {
+		t.Error("expected error for unknown id")
+	}
+}
+
+func TestMunicipioFromIbge(t *testing.T) {
+	m, err := MunicipioFromIbge(1200138)
+	if err != nil || m.Id != 1007 || m.Nome != "BUJARI" {
+		t.Error("expected BUJARI, got", m, err)
+	}
+
+	m, err = MunicipioFromIbge(3550308)
+	if err != nil || m.Id != 71072 {
+		t.Error("expected SAO PAULO, got", m, err)
+	}
+
+	_, err = MunicipioFromIbge(1)
+	if err == nil {
+		t.Error("expected error for unknown IBGE code")
+	}
+}
+
+func TestMunicipiosIbge(t *testing.T) {
+	reg, err := Municipios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var missing []string
+	for _, m := range reg.municipios {
+		if m.Ibge == 0 {
+			missing = append(missing, m.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		// Not an error until the correspondence file is imported.
+		t.Skipf("%d of %d municipios without IBGE code, e.g. %s", len(missing), len(reg.municipios), missing[0])
+	}
+}
+
+func TestMunicipioFromNome(t *testing.T) {
+	m, err := MunicipioFromNome("Simoes Filho", "")
+	if err != nil || m.Id != 39136 {
+		t.Error("expected accent-insensitive match, got", m, err)
+	}
+
+	m, err = MunicipioFromNome("bom jesus", "rs")
+	if err != nil || m.Id != 85456 {
+		t.Error("expected match in UF, got", m, err)
+	}
+
+	_, err = MunicipioFromNome("Bom Jesus", "")
+	if !errors.Is(err, ErrAmbiguousMunicipio) {
+		t.Error("expected ambiguous name, got", err)
+	}
+}
+
+func TestRegistroMunicipios(t *testing.T) {
+	reg, err := NewRegistroMunicipios(strings.NewReader("1007,BUJARI,AC,1200138\n1120,ACRELANDIA,AC\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := reg.ByIbge(1200138)
+	if !ok || m.Id != 1007 {
+		t.Error("expected match by IBGE code, got", m)
+	}
+
+	m, _ = reg.ById(1120)
+	if m.Ibge != 0 {
+		t.Error("expected no IBGE code, got", m.Ibge)
+	}
+
+	if len(reg.ByUf("ac")) != 2 {
+		t.Error("expected 2 municipios in AC, got", reg.ByUf("ac"))
+	}
+
+	_, err = NewRegistroMunicipios(strings.NewReader("1007,BUJARI\n"))
+	if err == nil {
+		t.Error("expected error for missing fields")
+	}
+}
+
+func TestMunicipiosByUf(t *testing.T) {
+	reg, err := Municipios()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(reg.ByUf("AC")); n != 22 {
+		t.Error("expected 22 municipios in AC, got", n)
+	}
+}
@@ -1,8 +1,12 @@
 package ue
 
 import (
+	"fmt"
 	"github.com/google/certificate-transparency-go/asn1"
 	"os"
+	"strconv"
+
+	"golang.org/x/exp/slices"
 )
 
 func ReadRdv(file string) (EntidadeResultadoRDV, error) {
@@ -19,3 +23,111 @@ func ReadRdv(file string) (EntidadeResultadoRDV, error) {
 
 	return rdv, nil
 }
+
+// Counts RDV votes with the same keys as CountVotosBu (votável number, Branco or Nulo).
+func CountVotosRdv(rdv EntidadeResultadoRDV, cargos []CargoConstitucional) (map[CargoConstitucional]map[string]int, error) {
+	votosPorCargo := make(map[CargoConstitucional]map[string]int)
+	for _, cargo := range cargos {
+		votosPorCargo[cargo] = map[string]int{}
+	}
+
+	eleicoes, err := readEleicoesGenericas(rdv)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range eleicoes {
+		for _, vc := range e.GetVotosCargos() {
+			id, err := vc.ReadIdCargo()
+			if err != nil {
+				return nil, err
+			}
+
+			cargo, ok := id.(CargoConstitucional)
+			if !ok || !slices.Contains(cargos, cargo) {
+				continue
+			}
+
+			for _, v := range vc.Votos {
+				tipo, err := TipoVotoRdvFromEnumerated(v.TipoVoto)
+				if err != nil {
+					return nil, err
+				}
+
+				switch tipo {
+				case NominalRdv, LegendaRdv:
+					codigo, err := strconv.Atoi(string(v.Digitacao))
+					if err != nil {
+						return nil, err
+					}
+					votosPorCargo[cargo][fmt.Sprint(codigo)]++
+				case BrancoRdv, BrancoAposSuspensaoRdv:
+					votosPorCargo[cargo][Branco.String()]++
+				case NuloRdv, NuloAposSuspensaoRdv, NuloPorRepeticaoRdv, NuloCargoSemCandidatoRdv, NuloAposSuspensaoCargoSemCandidatoRdv:
+					votosPorCargo[cargo][Nulo.String()]++
+				}
+			}
+		}
+	}
+
+	return votosPorCargo, nil
+}
+
+// One vote of an RDV with its cargo resolved.
+type VotoRdv struct {
+	IdEleicao int
+	Cargo     string
+	TipoVoto  TipoVotoRdv
+	Digitacao VotoDigitado // Empty for Branco and Nulo.
+}
+
+// Lists the votes of all cargos of the RDV in file order.
+func ListVotosRdv(rdv EntidadeResultadoRDV) ([]VotoRdv, error) {
+	eleicoes, err := readEleicoesGenericas(rdv)
+	if err != nil {
+		return nil, err
+	}
+
+	var votos []VotoRdv
+	for _, e := range eleicoes {
+		for _, vc := range e.GetVotosCargos() {
+			c, err := vc.ReadIdCargo()
+			if err != nil {
+				return nil, err
+			}
+			cargo := fmt.Sprint(c)
+
+			for _, v := range vc.Votos {
+				tipo, err := TipoVotoRdvFromEnumerated(v.TipoVoto)
+				if err != nil {
+					return nil, err
+				}
+
+				votos = append(votos, VotoRdv{e.GetId(), cargo, tipo, v.Digitacao})
+			}
+		}
+	}
+
+	return votos, nil
+}
+
+func readEleicoesGenericas(rdv EntidadeResultadoRDV) ([]EleicaoGenerica, error) {
+	el, err := rdv.Rdv.ReadEleicoes()
+	if err != nil {
+		return nil, err
+	}
+
+	var eleicoes []EleicaoGenerica
+	switch el := el.(type) {
+	case []EleicaoVota:
+		for _, e := range el {
+			eleicoes = append(eleicoes, e)
+		}
+	case []EleicaoSA:
+		for _, e := range el {
+			eleicoes = append(eleicoes, e)
+		}
+	}
+
+	return eleicoes, nil
+}
@@ -24,3 +24,27 @@ func TestRdv(t *testing.T) {
 		}
 	}
 }
+
+func TestListVotosRdv(t *testing.T) {
+	rdv, err := ReadRdv("test-data/urna.rdv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	votos, err := ListVotosRdv(rdv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(votos) == 0 {
+		t.Fatal("expected votes")
+	}
+
+	for _, v := range votos {
+		switch v.TipoVoto {
+		case NominalRdv, LegendaRdv:
+			if len(v.Digitacao) == 0 {
+				t.Error("expected digitacao for", v)
+			}
+		}
+	}
+}
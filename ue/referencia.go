@@ -0,0 +1,275 @@
+package ue
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"golang.org/x/exp/slices"
+)
+
+// Reference data (municípios and cargos) valid for some pleitos; a reference
+// without pleitos is the default for pleitos no other reference covers. One
+// pack per election is embedded; LoadReferencias reads other ones.
+type Referencia struct {
+	Nome       string
+	Pleitos    []IDPleito
+	Cargos     []CargoConstitucional // Cargos of the election; nil if unknown.
+	Municipios *RegistroMunicipios
+}
+
+var (
+	referenciasMu sync.RWMutex
+	referencias   []*Referencia // Replaces the embedded data when not empty.
+)
+
+// Reads a reference directory: a pack with `municipios.csv` (see
+// NewRegistroMunicipios), an optional `pleitos.csv` with one pleito code per
+// line and an optional `cargos.csv` with one CargoConstitucional text per
+// line, or a directory whose subdirectories are such packs (e.g. one per
+// election year). At most one pack may be the default and a pleito may be
+// covered by only one pack.
+func LoadReferencias(dir string) ([]*Referencia, error) {
+	refs, err := readReferencias(os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+
+	if len(refs) == 1 && refs[0].Nome == "." {
+		refs[0].Nome = filepath.Base(dir)
+	}
+
+	return refs, nil
+}
+
+func readReferencias(fsys fs.FS) ([]*Referencia, error) {
+	_, err := fs.Stat(fsys, "municipios.csv")
+	if err == nil {
+		r, err := loadReferencia(fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		return []*Referencia{r}, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []*Referencia
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		r, err := loadReferencia(fsys, e.Name())
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+
+	if len(refs) == 0 {
+		return nil, errors.New("no municipios.csv found")
+	}
+
+	var padrao string
+	cobertos := make(map[IDPleito]string)
+	for _, r := range refs {
+		if len(r.Pleitos) == 0 {
+			if len(padrao) > 0 {
+				return nil, fmt.Errorf("%s and %s are both default references (no pleitos.csv)", padrao, r.Nome)
+			}
+			padrao = r.Nome
+		}
+
+		for _, p := range r.Pleitos {
+			if outra, ok := cobertos[p]; ok {
+				return nil, fmt.Errorf("pleito %d is in both %s and %s", p, outra, r.Nome)
+			}
+			cobertos[p] = r.Nome
+		}
+	}
+
+	return refs, nil
+}
+
+func loadReferencia(fsys fs.FS, dir string) (*Referencia, error) {
+	name := path.Join(dir, "municipios.csv")
+	m, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer m.Close()
+
+	r := &Referencia{Nome: dir}
+	r.Municipios, err = NewRegistroMunicipios(m)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	err = readLines(fsys, path.Join(dir, "pleitos.csv"), func(line string) error {
+		id, err := strconv.Atoi(line)
+		if err != nil {
+			return err
+		}
+		r.Pleitos = append(r.Pleitos, IDPleito(id))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readLines(fsys, path.Join(dir, "cargos.csv"), func(line string) error {
+		c := CargoConstitucionalFromString(line)
+		if c == 0 {
+			return fmt.Errorf("unknown cargo %q", line)
+		}
+		r.Cargos = append(r.Cargos, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Calls `add` with the first field of each line of the file, if it exists.
+func readLines(fsys fs.FS, name string, add func(string) error) error {
+	f, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		err = add(record[0])
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+	}
+}
+
+// Uses `refs` instead of the embedded data; with no default reference among
+// them, the embedded data remains the default.
+func UseReferencias(refs []*Referencia) {
+	referenciasMu.Lock()
+	defer referenciasMu.Unlock()
+
+	referencias = refs
+}
+
+// Loaded reference covering `pleito`, or the loaded default one, or the
+// embedded pack covering `pleito`, or the embedded default.
+func ReferenciaFor(pleito IDPleito) (*Referencia, error) {
+	cobre := func(r *Referencia) bool { return slices.Contains(r.Pleitos, pleito) }
+	r := findReferencia(cobre)
+	if r == nil {
+		r = findReferencia(func(r *Referencia) bool { return len(r.Pleitos) == 0 })
+	}
+	if r != nil {
+		return r, nil
+	}
+
+	embutidas, err := referenciasEmbutidas()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range embutidas {
+		if cobre(r) {
+			return r, nil
+		}
+	}
+
+	return embutidaPadrao(embutidas), nil
+}
+
+// The loaded reference without pleitos, or the embedded default.
+func referenciaPadrao() (*Referencia, error) {
+	r := findReferencia(func(r *Referencia) bool { return len(r.Pleitos) == 0 })
+	if r != nil {
+		return r, nil
+	}
+
+	embutidas, err := referenciasEmbutidas()
+	if err != nil {
+		return nil, err
+	}
+
+	return embutidaPadrao(embutidas), nil
+}
+
+func findReferencia(match func(*Referencia) bool) *Referencia {
+	referenciasMu.RLock()
+	defer referenciasMu.RUnlock()
+
+	for _, r := range referencias {
+		if match(r) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+var (
+	embutidasOnce sync.Once
+	embutidas     []*Referencia
+	embutidasErr  error
+)
+
+// Packs of resource/, one per election year, loaded once.
+func referenciasEmbutidas() ([]*Referencia, error) {
+	embutidasOnce.Do(func() {
+		fsys, err := fs.Sub(f, "resource")
+		if err != nil {
+			embutidasErr = err
+			return
+		}
+
+		embutidas, embutidasErr = readReferencias(fsys)
+	})
+
+	return embutidas, embutidasErr
+}
+
+// The embedded pack without pleitos, or the one of the latest election.
+func embutidaPadrao(embutidas []*Referencia) *Referencia {
+	for _, r := range embutidas {
+		if len(r.Pleitos) == 0 {
+			return r
+		}
+	}
+
+	return embutidas[len(embutidas)-1]
+}
@@ -0,0 +1,180 @@
+package ue
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func writeReferencia(t *testing.T, dir, municipios, pleitos string) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "municipios.csv"), []byte(municipios), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pleitos) > 0 {
+		err = os.WriteFile(filepath.Join(dir, "pleitos.csv"), []byte(pleitos), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReferencias(t *testing.T) {
+	dir := t.TempDir()
+	writeReferencia(t, filepath.Join(dir, "2022"), "88986,ACEGUÁ,RS,4300034\n", "406\n407\n")
+	writeReferencia(t, filepath.Join(dir, "padrao"), "88986,ACEGUA PADRAO,RS\n", "")
+
+	refs, err := LoadReferencias(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 {
+		t.Fatal("expected 2 references, got", len(refs))
+	}
+
+	UseReferencias(refs)
+	defer UseReferencias(nil)
+
+	r, err := ReferenciaFor(406)
+	if err != nil || r.Nome != "2022" {
+		t.Error("expected reference 2022, got", r, err)
+	}
+
+	r, err = ReferenciaFor(1)
+	if err != nil || r.Nome != "padrao" {
+		t.Error("expected default reference, got", r, err)
+	}
+
+	bu, err := BuEntry{Path: "test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m := bu.Municipio(); m.Nome != "ACEGUÁ" || m.Ibge != 4300034 {
+		t.Error("expected municipio of pleito 406, got", m)
+	}
+
+	if m := bu.IdentificacaoSecao.Municipio(); m.Nome != "ACEGUA PADRAO" {
+		t.Error("expected municipio of the default reference, got", m)
+	}
+
+	UseReferencias(nil)
+	if m := bu.Municipio(); m.Nome != "ACEGUA" {
+		t.Error("expected embedded municipio, got", m)
+	}
+}
+
+func TestReferenciasEmbutidas(t *testing.T) {
+	r, err := ReferenciaFor(407)
+	if err != nil || r.Nome != "2022" {
+		t.Fatal("expected embedded reference 2022, got", r, err)
+	}
+	if len(r.Cargos) != 10 || !slices.Contains(r.Cargos, Presidente) || slices.Contains(r.Cargos, Prefeito) {
+		t.Error("expected the cargos of a general election, got", r.Cargos)
+	}
+
+	// Pleitos no pack covers fall back to the latest election.
+	r, err = ReferenciaFor(1)
+	if err != nil || r.Nome != "2022" {
+		t.Error("expected embedded default, got", r, err)
+	}
+}
+
+func TestReferenciaCargos(t *testing.T) {
+	dir := t.TempDir()
+	writeReferencia(t, filepath.Join(dir, "2018"), "1007,BUJARI,AC\n", "1\n")
+	writeReferencia(t, filepath.Join(dir, "2020"), "1007,BUJARI,AC\n", "2\n")
+	err := os.WriteFile(filepath.Join(dir, "2020", "cargos.csv"), []byte("Prefeito\nvice prefeito\nVereador\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := LoadReferencias(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	UseReferencias(refs)
+	defer UseReferencias(nil)
+
+	r, err := ReferenciaFor(1)
+	if err != nil || r.Nome != "2018" || r.Cargos != nil {
+		t.Error("expected reference 2018 without cargos, got", r, err)
+	}
+
+	r, err = ReferenciaFor(2)
+	if err != nil || r.Nome != "2020" || !slices.Equal(r.Cargos, []CargoConstitucional{Prefeito, VicePrefeito, Vereador}) {
+		t.Error("expected reference 2020 with municipal cargos, got", r, err)
+	}
+
+	// Without a loaded default, the embedded packs still apply.
+	r, err = ReferenciaFor(406)
+	if err != nil || r.Nome != "2022" {
+		t.Error("expected embedded reference 2022, got", r, err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "2020", "cargos.csv"), []byte("Prefeito\nRei\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadReferencias(dir)
+	if err == nil || !strings.Contains(err.Error(), "cargos.csv:2") {
+		t.Error("expected error for an unknown cargo, got", err)
+	}
+}
+
+func TestLoadReferenciaPack(t *testing.T) {
+	dir := t.TempDir()
+	writeReferencia(t, dir, "1007,BUJARI,AC\n", "")
+
+	refs, err := LoadReferencias(dir)
+	if err != nil || len(refs) != 1 || len(refs[0].Pleitos) != 0 {
+		t.Error("expected one default reference, got", refs, err)
+	}
+
+	_, err = LoadReferencias(t.TempDir())
+	if err == nil {
+		t.Error("expected error for directory without municipios.csv")
+	}
+}
+
+func TestLoadReferenciasAmbiguous(t *testing.T) {
+	dir := t.TempDir()
+	writeReferencia(t, filepath.Join(dir, "a"), "1007,BUJARI,AC\n", "")
+	writeReferencia(t, filepath.Join(dir, "b"), "1007,BUJARI,AC\n", "")
+
+	_, err := LoadReferencias(dir)
+	if err == nil {
+		t.Error("expected error for two default references")
+	}
+
+	dir = t.TempDir()
+	writeReferencia(t, filepath.Join(dir, "a"), "1007,BUJARI,AC\n", "406\n")
+	writeReferencia(t, filepath.Join(dir, "b"), "1007,BUJARI,AC\n", "406\n407\n")
+
+	_, err = LoadReferencias(dir)
+	if err == nil {
+		t.Error("expected error for a pleito in two references")
+	}
+}
+
+func TestPleito(t *testing.T) {
+	bu, err := BuEntry{Path: "test-data/urna.bu"}.ReadBu()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := bu.Pleito()
+	if !ok || p != 406 {
+		t.Error("expected pleito 406, got", p, ok)
+	}
+}
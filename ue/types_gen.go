@@ -0,0 +1,1342 @@
+// Code generated by asn1gen from assinatura.asn1, bu.asn1, rdv.asn1; DO NOT EDIT.
+
+package ue
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/certificate-transparency-go/asn1"
+)
+
+// Tipos de algoritmos de assinatura (cepesc é o algoritmo padrão (ainda não há previsão de uso dos demais)).
+type AlgoritmoAssinatura byte
+
+const (
+	Rsa    AlgoritmoAssinatura = 1
+	Ecdsa  AlgoritmoAssinatura = 2
+	Cepesc AlgoritmoAssinatura = 3
+)
+
+// Value of the contents octets of an implicitly tagged AlgoritmoAssinatura.
+func AlgoritmoAssinaturaFromData(data []byte) (AlgoritmoAssinatura, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return AlgoritmoAssinaturaFromEnumerated(e)
+}
+
+func AlgoritmoAssinaturaFromEnumerated(e asn1.Enumerated) (AlgoritmoAssinatura, error) {
+	switch e {
+	case 1, 2, 3:
+		return AlgoritmoAssinatura(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v AlgoritmoAssinatura) String() string {
+	if v >= 1 && v <= 3 {
+		return [...]string{"RSA", "ECDSA", "CEPESC"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de algoritmos de hash (Todos os algoritmos devem ser suportados mas sha512 é o padrão).
+type AlgoritmoHash byte
+
+const (
+	Sha1   AlgoritmoHash = 1
+	Sha256 AlgoritmoHash = 2
+	Sha384 AlgoritmoHash = 3
+	Sha512 AlgoritmoHash = 4
+)
+
+// Value of the contents octets of an implicitly tagged AlgoritmoHash.
+func AlgoritmoHashFromData(data []byte) (AlgoritmoHash, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return AlgoritmoHashFromEnumerated(e)
+}
+
+func AlgoritmoHashFromEnumerated(e asn1.Enumerated) (AlgoritmoHash, error) {
+	switch e {
+	case 1, 2, 3, 4:
+		return AlgoritmoHash(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v AlgoritmoHash) String() string {
+	if v >= 1 && v <= 4 {
+		return [...]string{"SHA-1", "SHA-256", "SHA-384", "SHA-512"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de modelos de urna eletrônica.
+type ModeloUrna byte
+
+const (
+	Ue2009 ModeloUrna = 9  // Urna modelo 2009.
+	Ue2010 ModeloUrna = 10 // Urna modelo 2010.
+	Ue2011 ModeloUrna = 11 // Urna modelo 2011.
+	Ue2013 ModeloUrna = 13 // Urna modelo 2013.
+	Ue2015 ModeloUrna = 15 // Urna modelo 2015.
+	Ue2020 ModeloUrna = 20 // Urna modelo 2020.
+)
+
+// Value of the contents octets of an implicitly tagged ModeloUrna.
+func ModeloUrnaFromData(data []byte) (ModeloUrna, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return ModeloUrnaFromEnumerated(e)
+}
+
+func ModeloUrnaFromEnumerated(e asn1.Enumerated) (ModeloUrna, error) {
+	switch e {
+	case 9, 10, 11, 13, 15, 20:
+		return ModeloUrna(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v ModeloUrna) String() string {
+	switch v {
+	case Ue2009:
+		return "UE2009"
+	case Ue2010:
+		return "UE2010"
+	case Ue2011:
+		return "UE2011"
+	case Ue2013:
+		return "UE2013"
+	case Ue2015:
+		return "UE2015"
+	case Ue2020:
+		return "UE2020"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Entidade que engloba a lista de assinaturas utilizadas para assinar os arquivos para manter a integridade e segurança dos dados.
+type EntidadeAssinatura struct {
+	DataHoraCriacao      DataHoraJE            // Data e Hora da criacao do arquivo.
+	Versao               int                   // Versao do protocolo (Alterações devem gerar novo valor. Nas eleições de 2012 foi utilizado o enumerado de valor 1 a partir de 2014 utilizar o valor 2).
+	AutoAssinado         AutoAssinaturaDigital // Informações da auto assinatura digital.
+	ConteudoAutoAssinado []byte                // Conteúdo da assinatura do próprio arquivo.
+	CertificadoDigital   []byte                `asn1:"optional"` // Certificado digital da urna eletrônica.
+	ConjuntoChave        string                `asn1:"optional"` // Identificador do conjunto de chaves usado para assinar o pacote.
+}
+
+// Entidade responsável por gerar o arquivo de assinatura de todos os arquivos de resultados da urna.
+// Podendo ter dois tipos de assinatura (Hardware (HW) e Software (SW)).
+// Esses arquivos são informados na Mídia de Resultado quando a urna eletrônica é encerrada.
+type EntidadeAssinaturaResultado struct {
+	ModeloUrna   asn1.Enumerated    // Modelo da urna eletrônica.
+	AssinaturaSW EntidadeAssinatura // Assinatura realizada via software (normalmente CEPESC).
+	AssinaturaHW EntidadeAssinatura // Assinatura realizada via hardware de segurança da urna eletrônica.
+}
+
+// Informações do algoritmo de assinatura.
+type AlgoritmoAssinaturaInfo struct {
+	Algoritmo asn1.Enumerated // Tipo do algoritmo de assinatura.
+	Bits      int             // Tamanho da assinatura.
+}
+
+// Informações do algoritmo de hash.
+type AlgoritmoHashInfo struct {
+	Algoritmo asn1.Enumerated // Tipo do algoritmo de hash.
+}
+
+// Informações dos arquivos assinados.
+type Assinatura struct {
+	ArquivosAssinados []AssinaturaArquivo // Lista com Informações dos arquivos assinados.
+}
+
+// Informações do arquivo e da assinatura.
+type AssinaturaArquivo struct {
+	NomeArquivo string            // Nome do arquivo.
+	Assinatura  AssinaturaDigital // Assinatura digital do arquivo.
+}
+
+// Informações da assinatura digital
+type AssinaturaDigital struct {
+	Tamanho    int    // Tamanho da assinatura.
+	Hash       []byte // Hash da assinatura (Deve ser calculado uma única vez e ser utilizado também para o cálculo da assinatura).
+	Assinatura []byte // Assinatura (Gerado/verificado a partir do hash acima).
+}
+
+// Informações da auto assinatura digital.
+type AutoAssinaturaDigital struct {
+	Usuario             DescritorChave          // Nome do usuário (Geralmente uma seção) que realizou a assinatura do arquivo.
+	AlgoritmoHash       AlgoritmoHashInfo       // Algoritmo de hash utilizado para realizar a assinatura (Será o mesmo para as assinaturas de arquivos).
+	AlgoritmoAssinatura AlgoritmoAssinaturaInfo // Algoritmo utilizado para realizar a assinatura (Será o mesmo para as assinaturas de arquivos).
+	Assinatura          AssinaturaDigital       // Informações da assinatura digital.
+}
+
+// Identificador com informações da assinatura.
+type DescritorChave struct {
+	NomeUsuario string // Nome do usuário (Geralmente uma seção) que realizou a assinatura no arquivo.
+	Serial      int    // Data em que foi gerado o conjunto de chaves.
+}
+
+type CodigoMunicipio int // Código do município fornecido pelo cadastro da Justiça Eleitoral.
+
+type DataHoraJE string // Data e hora utilizada pela Justiça Eleitoral no formato YYYYMMDDThhmmss.
+
+type IDEleicao int // Código numérico identificador da <glossario id='eleicao'>eleição</glossario> (Atribuído pelo Sistema Configurador de Eleições).
+
+type IDPleito int // Código numérico identificador do <glossario id='pleito'>pleito</glossario> (Atribuído pelo Sistema Configurador de Eleições).
+
+type IDProcessoEleitoral int // Código numérico identificador do <glossario id='processo-eleitoral'>processo eleitoral</glossario> (Atribuído pelo Sistema Configurador de Eleções).
+
+type NumeroCargoConsultaLivre int // Número livre de cargo ou consulta definido no cadastramento da <glossario id='eleicao'>eleição</glossario>.
+
+type NumeroInternoUrna int // Número interno da urna eletrônica.
+
+type NumeroLocal int // Número do local de votação da <glossario id='secao-eleitoral'>seção eleitoral</glossario> de acordo com o cadastro da Justiça Eleitoral.
+
+type NumeroMesa int // Número da mesa de justificativa de acordo com o cadastro da Justiça Eleitoral (Informação referente ao Número da mesa utilizada para justificativa dos eleitores que não irão votar no seu domicílio eleitoral).
+
+type NumeroPartido int // Número do <glossario id='partido'>partido</glossario> fornecido pelo Sistema de Candidaturas da Justiça Eleitoral (Número do partido que compõe a <glossario id='coligacao'>coligação</glossario> ou do partido isolado).
+
+type NumeroSecao int // Número da <glossario id='secao-eleitoral'>seçõe eleitoral</glossario> de acordo com o cadastro da Justiça Eleitoral.
+
+type NumeroSerieFlash []byte // Número de série da Flash (Representa um número de 4 bytes (0..2^32-1)).
+
+type NumeroUrna int // Número da urna utilizada na mesa de justificativa de acordo com o cadastro da Justiça Eleitoral.
+
+type NumeroVotavel int // Número do <glossario id='votavel'>votável</glossario> fornecido pelo Sistema de Candidaturas da Justiça Eleitoral.
+
+type NumeroZona int // Número da <glossario id='zona-eleitoral'>zona eleitoral</glossario> fornecido pelo cadastro da Justiça Eleitoral.
+
+type CargoConstitucional byte
+
+const (
+	Presidente              CargoConstitucional = 1
+	VicePresidente          CargoConstitucional = 2
+	Governador              CargoConstitucional = 3
+	ViceGovernador          CargoConstitucional = 4
+	Senador                 CargoConstitucional = 5
+	DeputadoFederal         CargoConstitucional = 6
+	DeputadoEstadual        CargoConstitucional = 7
+	DeputadoDistrital       CargoConstitucional = 8
+	PrimeiroSuplenteSenador CargoConstitucional = 9
+	SegundoSuplenteSenador  CargoConstitucional = 10
+	Prefeito                CargoConstitucional = 11
+	VicePrefeito            CargoConstitucional = 12
+	Vereador                CargoConstitucional = 13
+)
+
+// Value of the contents octets of an implicitly tagged CargoConstitucional.
+func CargoConstitucionalFromData(data []byte) (CargoConstitucional, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return CargoConstitucionalFromEnumerated(e)
+}
+
+func CargoConstitucionalFromEnumerated(e asn1.Enumerated) (CargoConstitucional, error) {
+	switch e {
+	case 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13:
+		return CargoConstitucional(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v CargoConstitucional) String() string {
+	if v >= 1 && v <= 13 {
+		return [...]string{"Presidente", "Vice Presidente", "Governador", "Vice Governador", "Senador", "Deputado Federal", "Deputado Estadual", "Deputado Distrital", "Primeiro Suplente de Senador", "Segundo Suplente de Senador", "Prefeito", "Vice Prefeito", "Vereador"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type Fase byte
+
+const (
+	Simulado    Fase = 1
+	Oficial     Fase = 2
+	Treinamento Fase = 3
+)
+
+// Value of the contents octets of an implicitly tagged Fase.
+func FaseFromData(data []byte) (Fase, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return FaseFromEnumerated(e)
+}
+
+func FaseFromEnumerated(e asn1.Enumerated) (Fase, error) {
+	switch e {
+	case 1, 2, 3:
+		return Fase(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v Fase) String() string {
+	if v >= 1 && v <= 3 {
+		return [...]string{"Simulado", "Oficial", "Treinamento"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type MotivoApuracaoEletronica byte
+
+const (
+	NaoFoiPossivelReuperarResultado MotivoApuracaoEletronica = 1
+	UrnaNaoChegouMidiaDefeituosa    MotivoApuracaoEletronica = 2
+	UrnaNaoChegouMidiaExtraviada    MotivoApuracaoEletronica = 3
+	Outros                          MotivoApuracaoEletronica = 99
+)
+
+// Value of the contents octets of an implicitly tagged MotivoApuracaoEletronica.
+func MotivoApuracaoEletronicaFromData(data []byte) (MotivoApuracaoEletronica, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return MotivoApuracaoEletronicaFromEnumerated(e)
+}
+
+func MotivoApuracaoEletronicaFromEnumerated(e asn1.Enumerated) (MotivoApuracaoEletronica, error) {
+	switch e {
+	case 1, 2, 3, 99:
+		return MotivoApuracaoEletronica(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v MotivoApuracaoEletronica) String() string {
+	switch v {
+	case NaoFoiPossivelReuperarResultado:
+		return "Nao foi possivel recuperar resultado"
+	case UrnaNaoChegouMidiaDefeituosa:
+		return "Urna nao chegou (midia defeituosa)"
+	case UrnaNaoChegouMidiaExtraviada:
+		return "Urna nao chegou (midia extraviada)"
+	case Outros:
+		return "Outro"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type MotivoApuracaoManual byte
+
+const (
+	UrnaComDefeitoApMan    MotivoApuracaoManual = 1
+	UrnaIndisponivelInicio MotivoApuracaoManual = 2
+	UrnaOutraSecao         MotivoApuracaoManual = 3
+	OutrosApMan            MotivoApuracaoManual = 99
+)
+
+// Value of the contents octets of an implicitly tagged MotivoApuracaoManual.
+func MotivoApuracaoManualFromData(data []byte) (MotivoApuracaoManual, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return MotivoApuracaoManualFromEnumerated(e)
+}
+
+func MotivoApuracaoManualFromEnumerated(e asn1.Enumerated) (MotivoApuracaoManual, error) {
+	switch e {
+	case 1, 2, 3, 99:
+		return MotivoApuracaoManual(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v MotivoApuracaoManual) String() string {
+	switch v {
+	case UrnaComDefeitoApMan:
+		return "Urna com defeito"
+	case UrnaIndisponivelInicio:
+		return "Urna indisponivel no inicio"
+	case UrnaOutraSecao:
+		return "Urna de outra secao"
+	case OutrosApMan:
+		return "Outro"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type MotivoApuracaoMistaComBU byte
+
+const (
+	UrnaDataHoraIncorreta       MotivoApuracaoMistaComBU = 1
+	UrnaComDefeito              MotivoApuracaoMistaComBU = 2
+	UrnaOutrasecao              MotivoApuracaoMistaComBU = 3
+	UrnaPreparadaIncorretamente MotivoApuracaoMistaComBU = 4
+	UrnaChegouAposInicioVotacao MotivoApuracaoMistaComBU = 5
+	OutrosApMisBu               MotivoApuracaoMistaComBU = 99
+)
+
+// Value of the contents octets of an implicitly tagged MotivoApuracaoMistaComBU.
+func MotivoApuracaoMistaComBUFromData(data []byte) (MotivoApuracaoMistaComBU, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return MotivoApuracaoMistaComBUFromEnumerated(e)
+}
+
+func MotivoApuracaoMistaComBUFromEnumerated(e asn1.Enumerated) (MotivoApuracaoMistaComBU, error) {
+	switch e {
+	case 1, 2, 3, 4, 5, 99:
+		return MotivoApuracaoMistaComBU(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v MotivoApuracaoMistaComBU) String() string {
+	switch v {
+	case UrnaDataHoraIncorreta:
+		return "Urna com data/hora incorreta"
+	case UrnaComDefeito:
+		return "Urna com defeito"
+	case UrnaOutrasecao:
+		return "Urna de outra secao"
+	case UrnaPreparadaIncorretamente:
+		return "Urna preparada incorretamente"
+	case UrnaChegouAposInicioVotacao:
+		return "Urna chegou apos inicio da votacao"
+	case OutrosApMisBu:
+		return "Outro"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type MotivoApuracaoMistaComMR byte
+
+const (
+	NaoObteveExitoContingencia         MotivoApuracaoMistaComMR = 1
+	IndisponibilidadeUrnaContingencia  MotivoApuracaoMistaComMR = 2
+	IndisponibilidadeFlashContingencia MotivoApuracaoMistaComMR = 3
+	ProblemaEnergiaEletrica            MotivoApuracaoMistaComMR = 4
+	NaoFoiPossivelTrocarUrna           MotivoApuracaoMistaComMR = 5
+	NaoFoiSolicitadaTrocaUrna          MotivoApuracaoMistaComMR = 6
+	OutrosApMisMr                      MotivoApuracaoMistaComMR = 99
+)
+
+// Value of the contents octets of an implicitly tagged MotivoApuracaoMistaComMR.
+func MotivoApuracaoMistaComMRFromData(data []byte) (MotivoApuracaoMistaComMR, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return MotivoApuracaoMistaComMRFromEnumerated(e)
+}
+
+func MotivoApuracaoMistaComMRFromEnumerated(e asn1.Enumerated) (MotivoApuracaoMistaComMR, error) {
+	switch e {
+	case 1, 2, 3, 4, 5, 6, 99:
+		return MotivoApuracaoMistaComMR(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v MotivoApuracaoMistaComMR) String() string {
+	switch v {
+	case NaoObteveExitoContingencia:
+		return "Nao obteve exito contingencia"
+	case IndisponibilidadeUrnaContingencia:
+		return "IndisponibilidadeUrnaContingencia"
+	case IndisponibilidadeFlashContingencia:
+		return "IndisponibilidadeFlashContingencia"
+	case ProblemaEnergiaEletrica:
+		return "ProblemaEnergiaEletrica"
+	case NaoFoiPossivelTrocarUrna:
+		return "NaoFoiPossivelTrocarUrna"
+	case NaoFoiSolicitadaTrocaUrna:
+		return "NaoFoiSolicitadaTrocaUrna"
+	case OutrosApMisMr:
+		return "Outro"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type TipoApuracao byte
+
+const (
+	TotalmenteManual     TipoApuracao = 1
+	TotalmenteEletronica TipoApuracao = 2
+	MistaBU              TipoApuracao = 3
+	MistaMR              TipoApuracao = 4
+	TipoApuracaoNormal   TipoApuracao = 5
+)
+
+// Value of the contents octets of an implicitly tagged TipoApuracao.
+func TipoApuracaoFromData(data []byte) (TipoApuracao, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoApuracaoFromEnumerated(e)
+}
+
+func TipoApuracaoFromEnumerated(e asn1.Enumerated) (TipoApuracao, error) {
+	switch e {
+	case 1, 2, 3, 4, 5:
+		return TipoApuracao(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoApuracao) String() string {
+	if v >= 1 && v <= 5 {
+		return [...]string{"TotalmenteManual", "TotalmenteEletronica", "MistaBU", "MistaMR", "Normal"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de arquivos de votação.
+type TipoArquivo byte
+
+const (
+	VotacaoUE               TipoArquivo = 1 // Urna eletrônica.
+	VotacaoRED              TipoArquivo = 2 // RED (Recuperador de Dados - Responsável por gerar uma nova memória de resultado a partir da urna originária).
+	SaMistaMRParcialCedula  TipoArquivo = 3 // <glossario id='sistema-de-apuracao'>Sistema de Apuração</glossario> (Votação Mista - Memória de Resultado e Cédulas).
+	SaMistaBUImpressoCedula TipoArquivo = 4 // <glossario id='sistema-de-apuracao'>Sistema de Apuração</glossario> (Votação Mista - <glossario id='boletim-de-urna'>BU</glossario> impresso e Cédulas).
+	SaManual                TipoArquivo = 5 // <glossario id='sistema-de-apuracao'>Sistema de Apuração</glossario> (Votação totalmente manual - Cédulas).
+	SaEletronica            TipoArquivo = 6 // <glossario id='sistema-de-apuracao'>Sistema de Apuração</glossario> (Votação totalmente eletrônica).
+)
+
+// Value of the contents octets of an implicitly tagged TipoArquivo.
+func TipoArquivoFromData(data []byte) (TipoArquivo, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoArquivoFromEnumerated(e)
+}
+
+func TipoArquivoFromEnumerated(e asn1.Enumerated) (TipoArquivo, error) {
+	switch e {
+	case 1, 2, 3, 4, 5, 6:
+		return TipoArquivo(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoArquivo) String() string {
+	if v >= 1 && v <= 6 {
+		return [...]string{"VotacaoUE", "VotacaoRED", "SaMistaMRParcialCedula", "SaMistaBUImpressoCedula", "SaManual", "SaEletronica"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de cargos ou consultas da <glossario id='eleicao'>eleição</glossario>.
+type TipoCargoConsulta byte
+
+const (
+	Majoritario  TipoCargoConsulta = 1 // <glossario id='cargo-majoritario'>Cargos majoritários</glossario>.
+	Proporcional TipoCargoConsulta = 2 // <glossario id='cargo-proporcional'>Cargos proporcionais</glossario>.
+	Consulta     TipoCargoConsulta = 3 // São as perguntas da <glossario id='consulta-popular'>consulta popular</glossario>.
+)
+
+// Value of the contents octets of an implicitly tagged TipoCargoConsulta.
+func TipoCargoConsultaFromData(data []byte) (TipoCargoConsulta, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoCargoConsultaFromEnumerated(e)
+}
+
+func TipoCargoConsultaFromEnumerated(e asn1.Enumerated) (TipoCargoConsulta, error) {
+	switch e {
+	case 1, 2, 3:
+		return TipoCargoConsulta(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoCargoConsulta) String() string {
+	if v >= 1 && v <= 3 {
+		return [...]string{"Majoritario", "Proporcional", "Consulta"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de envelopes dos arquivos.
+type TipoEnvelope byte
+
+const (
+	EnvelopeBoletimUrna         TipoEnvelope = 1 // Boletim de Urna (BU).
+	EnvelopeRegistroDigitalVoto TipoEnvelope = 2 // Registro Digital do Voto (RDV).
+	EnvelopeBoletimUrnaImpresso TipoEnvelope = 4 // Boletim de Urna impresso.
+	EnvelopeImagemBiometria     TipoEnvelope = 5 // Arquivo de imagem de biometria.
+)
+
+// Value of the contents octets of an implicitly tagged TipoEnvelope.
+func TipoEnvelopeFromData(data []byte) (TipoEnvelope, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoEnvelopeFromEnumerated(e)
+}
+
+func TipoEnvelopeFromEnumerated(e asn1.Enumerated) (TipoEnvelope, error) {
+	switch e {
+	case 1, 2, 4, 5:
+		return TipoEnvelope(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoEnvelope) String() string {
+	switch v {
+	case EnvelopeBoletimUrna:
+		return "EnvelopeBoletimUrna"
+	case EnvelopeRegistroDigitalVoto:
+		return "EnvelopeRegistroDigitalVoto"
+	case EnvelopeBoletimUrnaImpresso:
+		return "EnvelopeBoletimUrnaImpresso"
+	case EnvelopeImagemBiometria:
+		return "EnvelopeImagemBiometria"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de urna eletrônica.
+type TipoUrna byte
+
+const (
+	Secao                  TipoUrna = 1 // Urna de seção.
+	Contingencia           TipoUrna = 3 // <glossario id='urna-de-contingencia'>Urna de contingência</glossario>.
+	ReservaSecao           TipoUrna = 4 // Resultado de urna de contingência que passou a ser de seção.
+	ReservaEncerrandoSecao TipoUrna = 6 // Barriga de aluguel para seção (Procedimento de recuperação dos dados de uma urna de seção, a partir da inserção de seu cartão de memória externo em uma <glossario id='urna-de-contingencia'>urna de contingência</glossario>).
+)
+
+// Value of the contents octets of an implicitly tagged TipoUrna.
+func TipoUrnaFromData(data []byte) (TipoUrna, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoUrnaFromEnumerated(e)
+}
+
+func TipoUrnaFromEnumerated(e asn1.Enumerated) (TipoUrna, error) {
+	switch e {
+	case 1, 3, 4, 6:
+		return TipoUrna(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoUrna) String() string {
+	switch v {
+	case Secao:
+		return "Secao"
+	case Contingencia:
+		return "Contingencia"
+	case ReservaSecao:
+		return "ReservaSecao"
+	case ReservaEncerrandoSecao:
+		return "ReservaEncerrandoSecao"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipos de votos existentes na urna eletrônica.
+type TipoVoto byte
+
+const (
+	Nominal           TipoVoto = 1 // <glossario id='votos-nominais'>Voto nominal.</glossario>
+	Branco            TipoVoto = 2 // Voto branco.
+	Nulo              TipoVoto = 3 // Voto nulo.
+	Legenda           TipoVoto = 4 // <glossario id='votos-de-legenda'>Voto de legenda.</glossario>
+	CargoSemCandidato TipoVoto = 5 // Nenhum candidato para ser votado no cargo.
+)
+
+// Value of the contents octets of an implicitly tagged TipoVoto.
+func TipoVotoFromData(data []byte) (TipoVoto, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoVotoFromEnumerated(e)
+}
+
+func TipoVotoFromEnumerated(e asn1.Enumerated) (TipoVoto, error) {
+	switch e {
+	case 1, 2, 3, 4, 5:
+		return TipoVoto(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoVoto) String() string {
+	if v >= 1 && v <= 5 {
+		return [...]string{"Nominal", "Branco", "Nulo", "Legenda", "CargoSemCandidato"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Identificador que contém informações do cabeçalho da entidade (Arquivos ASN.1).
+type CabecalhoEntidade struct {
+	DataGeracao DataHoraJE    // Data da geração da entidade.
+	IdEleitoral asn1.RawValue // Identificador Eleitoral (<glossario id='processo'>Processo</glossario> <glossario id='pleito'>pleito</glossario> ou <glossario id='eleicao'>eleição</glossario>).
+}
+
+// Identificador com informações da urna eletrônica.
+type Urna struct {
+	TipoUrna                 asn1.Enumerated          // Tipo da urna eletrônica.
+	VersaoVotacao            string                   // Versão do software de votação da urna eletrônica.
+	CorrespondenciaResultado CorrespondenciaResultado // Informações da <glossario id='correspondencia'>correspondência</glossario> da urna eletrônica.
+	TipoArquivo              asn1.Enumerated          // Tipo do arquivo gerado pela urna eletrônica.
+	NumeroSerieFV            NumeroSerieFlash         // Número de série da Flash de Votação.
+	MotivoUtilizacaoSA       asn1.RawValue            `asn1:"optional"` // Identificador numérico para o motivo de utilização do <glossario id='sistema-de-apuracao'>Sistema de Apuração</glossario> para a urna eletrônica.
+}
+
+// Entidade responsável por envelopar os arquivos ou dados binários da urna eletrônica.
+// Transforma os arquivos da urna eletrônica em arquivos no padrão ASN.1 assinados e algumas vezes criptografados.
+type EntidadeEnvelopeGenerico struct {
+	Cabecalho     CabecalhoEntidade // Informações do cabeçalho da entidade.
+	Fase          asn1.Enumerated   // Fase em que foi gerado o arquivo.
+	Urna          Urna              `asn1:"optional"` // Informações da urna eletrônica (Deve existir para RDV e ser omitido no BU).
+	Identificacao asn1.RawValue     // Identificação se é urna de seção eleitoral ou de Mesa Receptora de Justificativa.
+	TipoEnvelope  asn1.Enumerated   // Tipo de envelope que será criado.
+	Seguranca     Seguranca         `asn1:"optional"` // Informações de segurança solicitados pela biblioteca do CEPESC (Existindo o conteúdo estará cifrado).
+	Conteudo      []byte            // Conteúdo do envelope gerado.
+}
+
+// Identificador com informações de segurança solicitados pela biblioteca do CEPESC.
+type Seguranca struct {
+	IdTipoArquivo  int    // Identificador que corresponde ao arquivo solicitado pela biblioteca do CEPESC.
+	IdCriptografia int    // Identificador que corresponde ao Turno solicitado pela biblioteca do CEPESC.
+	IdArquivoCD    int    // Identificador do arquivo solicitado pela biblioteca do CEPESC.
+	IdArquivoChave []byte // Chave pública para cifrar o arquivo.
+}
+
+// Entidade responsável por apresentar as informações do <glossario id='boletim-de-urna'>boletim de urna</glossario>.
+type EntidadeBoletimUrna struct {
+	Cabecalho                   CabecalhoEntidade            // Informações do cabeçalho da entidade.
+	Fase                        asn1.Enumerated              // Fase em que foi gerado o arquivo.
+	Urna                        Urna                         // Informações da urna eletrônica.
+	IdentificacaoSecao          IdentificacaoSecaoEleitoral  // Informações da <glossario id='secao-eleitoral'>seção eleitoral</glossario> que está instalada a urna eletrônica.
+	DataHoraEmissao             DataHoraJE                   // Data e hora da emissão do boletim de urna.
+	DadosSecaoSA                asn1.RawValue                // Identificação para resultado de urna de seção ou <glossario id='sistema-de-apuracao'>de Sistema de Apuração</glossario>.
+	QtdEleitoresLibCodigo       int                          `asn1:"tag:1,optional"` // Quantidade de eleitores que compareceram que foram habilitados manualmente.
+	QtdEleitoresCompBiometrico  int                          `asn1:"tag:2,optional"` // Quantidade de eleitores que compareceram que utilizaram <glossario id='identificacao-biometrica'>biometria</glossario>.
+	ResultadosVotacaoPorEleicao []ResultadoVotacaoPorEleicao `asn1:"tag:3"`          // Lista com os resultados da votação para cada eleição.
+	HistoricoCorrespondencias   []CorrespondenciaResultado   `asn1:"tag:4,optional"` // Lista com informações de histórico das <glossario id='correspondencia'>correspondências</glossario> (Pode ser opcional porque quando o BU é da urna original não existe esse histórico).
+	HistoricoVotoImpresso       []HistoricoVotoImpresso      `asn1:"tag:5,optional"` // Lista com informações de histórico de voto impresso.
+	ChaveAssinaturaVotosVotavel []byte                       // Chave de assinatura pública das tuplas dos votáveis.
+}
+
+func (ApuracaoMistaMR) isApuracao()                     {}
+func (ApuracaoMistaBUAE) isApuracao()                   {}
+func (ApuracaoTotalmenteManualDigitacaoAE) isApuracao() {}
+func (ApuracaoEletronica) isApuracao()                  {}
+
+// CHOICE de Urna.motivoUtilizacaoSA (ausente na apuração normal).
+// Result is one of (ApuracaoMistaMR, ApuracaoMistaBUAE, ApuracaoTotalmenteManualDigitacaoAE, ApuracaoEletronica)
+func readApuracao(raw asn1.RawValue) (Apuracao, error) {
+	switch raw.Tag {
+	case 0:
+		var v ApuracaoMistaMR
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 1:
+		var v ApuracaoMistaBUAE
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 2:
+		var v ApuracaoTotalmenteManualDigitacaoAE
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 3:
+		var v ApuracaoEletronica
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return nil, errors.New("could not read Apuracao")
+}
+
+type ApuracaoEletronica struct {
+	Tipoapuracao   asn1.Enumerated
+	MotivoApuracao asn1.Enumerated
+}
+
+type ApuracaoMistaBUAE struct {
+	Tipoapuracao   asn1.Enumerated
+	MotivoApuracao asn1.Enumerated
+}
+
+type ApuracaoMistaMR struct {
+	TipoApuracao   asn1.Enumerated
+	MotivoApuracao asn1.Enumerated
+}
+
+type ApuracaoTotalmenteManualDigitacaoAE struct {
+	Tipoapuracao   asn1.Enumerated
+	MotivoApuracao asn1.Enumerated
+}
+
+// Identificador com informações da carga da urna eletrônica.
+type Carga struct {
+	NumeroInternoUrna NumeroInternoUrna // Número interno da urna eletrônica.
+	NumeroSerieFC     NumeroSerieFlash  // Número de série da unidade de Flash Card.
+	DataHoraCarga     DataHoraJE        // Data e hora da carga no formato utilizado pela Justiça Eleitoral (YYYYMMDDThhmmss).
+	CodigoCarga       string            // Código da carga da urna eletrônica.
+}
+
+// Código do cargo ou da consulta.
+type CodigoCargoConsulta interface {
+	isCodigoCargoConsulta()
+}
+
+func (CargoConstitucional) isCodigoCargoConsulta()      {}
+func (NumeroCargoConsultaLivre) isCodigoCargoConsulta() {}
+
+// Result is one of (CargoConstitucional, NumeroCargoConsultaLivre)
+func readCodigoCargoConsulta(raw asn1.RawValue) (CodigoCargoConsulta, error) {
+	switch raw.Tag {
+	case 1:
+		v, err := CargoConstitucionalFromData(raw.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 2:
+		var v NumeroCargoConsultaLivre
+		_, err := asn1.Unmarshal(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return nil, errors.New("could not read CodigoCargoConsulta")
+}
+
+// Identificador com informações da urna e da carga.
+type CorrespondenciaResultado struct {
+	Identificacao asn1.RawValue // Identificação se  tem carga de seção ou de mesa receptora de justificativa.
+	Carga         Carga         // Informações da carga da urna eletrônica.
+}
+
+// CHOICE de EntidadeBoletimUrna.dadosSecaoSA.
+type DadosSecaoSA interface {
+	isDadosSecaoSA()
+}
+
+func (DadosSecao) isDadosSecaoSA() {}
+func (DadosSA) isDadosSecaoSA()    {}
+
+// Result is one of (DadosSecao, DadosSA)
+func readDadosSecaoSA(raw asn1.RawValue) (DadosSecaoSA, error) {
+	switch raw.Tag {
+	case 0:
+		var v DadosSecao
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 1:
+		var v DadosSA
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return nil, errors.New("could not read DadosSecaoSA")
+}
+
+// Identificador com informações do <glossario id='boletim-de-urna'>BU</glossario>) de <glossario id='sistema-de-apuracao'>SA</glossario>).
+type DadosSA struct {
+	JuntaApuradora          int               // Número da junta eleitoral responsával pela apuração dos votos.
+	TurmaApuradora          int               // Número da turma apuradora responsával pela apuração dos votos.
+	NumeroInternoUrnaOrigem NumeroInternoUrna // Número interno da urna eletrônica com impossibilidade de utilização.
+}
+
+// Identificador com informações do <glossario id='boletim-de-urna'>BU</glossario>) de seção.
+type DadosSecao struct {
+	DataHoraAbertura                 DataHoraJE // Data e hora do início da aquisição do voto (Primeiro voto) no formato adotado pela Justiça Eleitoral (YYYYMMDDThhmmss).
+	DataHoraEncerramento             DataHoraJE // Data e hora do término da aquisição do voto (Último voto) no formato adotado pela Justiça Eleitoral (YYYYMMDDThhmmss).
+	DataHoraDesligamentoVotoImpresso DataHoraJE `asn1:"optional"` // Data e hora do desligamento da impressão do voto (somente se tinha voto impresso na seção e se ocorreu o cancelamento) (YYYYMMDDThhmmss).
+}
+
+// Identificador com informações de histórico de voto impresso
+type HistoricoVotoImpresso struct {
+	IdImpressoraVotos  int        // Número interno da impressora de votos
+	IdRepositorioVotos int        // Número interno do repositório de votos
+	DataHoraLigamento  DataHoraJE // Data e hora do momento que o dispositivo for ligado
+}
+
+// CHOICE de CabecalhoEntidade.idEleitoral.
+type IdEleitoral interface {
+	isIdEleitoral()
+}
+
+func (IDProcessoEleitoral) isIdEleitoral() {}
+func (IDPleito) isIdEleitoral()            {}
+func (IDEleicao) isIdEleitoral()           {}
+
+// Result is one of (IDProcessoEleitoral, IDPleito, IDEleicao)
+func readIdEleitoral(raw asn1.RawValue) (IdEleitoral, error) {
+	switch raw.Tag {
+	case 1:
+		var n int
+		_, err := asn1.UnmarshalWithParams(raw.FullBytes, &n, "tag:1")
+		if err != nil {
+			return nil, err
+		}
+		return IDProcessoEleitoral(n), nil
+	case 2:
+		var n int
+		_, err := asn1.UnmarshalWithParams(raw.FullBytes, &n, "tag:2")
+		if err != nil {
+			return nil, err
+		}
+		return IDPleito(n), nil
+	case 3:
+		var n int
+		_, err := asn1.UnmarshalWithParams(raw.FullBytes, &n, "tag:3")
+		if err != nil {
+			return nil, err
+		}
+		return IDEleicao(n), nil
+	}
+
+	return nil, errors.New("could not read IdEleitoral")
+}
+
+// Identificador com informações de <glossario id='contingencia'>contingência</glossario>.
+type IdentificacaoContingencia struct {
+	MunicipioZona MunicipioZona // Número do município e Número da <glossario id='zona-eleitoral'>zona eleitoral</glossario> a qual pertence a urna.
+}
+
+// Identificador com informações da mesa receptora de justificativa.
+type IdentificacaoMesaJustificativa struct {
+	MunicipioZona MunicipioZona // Número do município e Número da <glossario id='zona-eleitoral'>zona eleitoral</glossario>.
+	Mesa          NumeroMesa    // Número da mesa de justificativa.
+	Urna          NumeroUrna    // Número da urna de justificativa.
+}
+
+// Identificador com informações da <glossario id='secao-eleitoral'>seção eleitoral</glossario>.
+type IdentificacaoSecaoEleitoral struct {
+	MunicipioZona MunicipioZona // Número do município e Número da <glossario id='zona-eleitoral'>zona eleitoral</glossario> a qual pertence a <glossario id='secao-eleitoral'>seção eleitoral</glossario>.
+	Local         NumeroLocal   // Número do local de votação da seção eleitoral.
+	Secao         NumeroSecao   // Número identificador da <glossario id='secao-eleitoral'>seção eleitoral</glossario>.
+}
+
+func (IdentificacaoSecaoEleitoral) isIdentificacaoUrna()    {}
+func (IdentificacaoContingencia) isIdentificacaoUrna()      {}
+func (IdentificacaoMesaJustificativa) isIdentificacaoUrna() {}
+
+// CHOICE da identificação de CorrespondenciaResultado e EntidadeEnvelopeGenerico.
+// Result is one of (IdentificacaoSecaoEleitoral, IdentificacaoContingencia, IdentificacaoMesaJustificativa)
+func readIdentificacaoUrna(raw asn1.RawValue) (IdentificacaoUrna, error) {
+	switch raw.Tag {
+	case 0:
+		var v IdentificacaoSecaoEleitoral
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 1:
+		var v IdentificacaoContingencia
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 2:
+		var v IdentificacaoMesaJustificativa
+		err := FillSequence(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return nil, errors.New("could not read IdentificacaoUrna")
+}
+
+// Identificação de um votável que pode ser um candidato ou uma pergunta de consulta popular.
+type IdentificacaoVotavel struct {
+	Partido NumeroPartido // Número do partido.
+	Codigo  NumeroVotavel // Número do votável.
+}
+
+// Identificador que contém informações de município e <glossario id='zona eleitoral'>zona eleitoral</glossario> que são relacionados entre si.
+type MunicipioZona struct {
+	Municipio CodigoMunicipio // Código do município de acordo com o cadastro da Justiça Eleitoral.
+	Zona      NumeroZona      // Número da <glossario id='zona eleitoral'>zona eleitoral</glossario> de acordo com o cadastro da Justiça Eleitoral.
+}
+
+// Identificador com informações do resultado de votação da urna eletrônica.
+type ResultadoVotacao struct {
+	TipoCargo         asn1.Enumerated   // Tipo do cargo ou consulta.
+	QtdComparecimento int               // Quantidade de eleitores que compareceram à seção para votação no cargo ou consulta.
+	TotaisVotosCargo  []TotalVotosCargo // Quantidade total de votos para cada cargo ou consulta.
+}
+
+// Estrutura com os resultados da votação de uma eleição.
+type ResultadoVotacaoPorEleicao struct {
+	IdEleicao         IDEleicao          // Identificador numérico da <glossario id='eleicao'>eleição</glossario>.
+	QtdEleitoresAptos int                // Quantidade de <glossario id='eleitor'>eleitores</glossario> aptos a votar na urna eletrônica da seção.
+	ResultadosVotacao []ResultadoVotacao // Lista com informações do resultado da votação na urna eletrônica.
+}
+
+// Identificador com informações do total de votos para cada cargo ou consulta.
+type TotalVotosCargo struct {
+	CodigoCargo    asn1.RawValue       // Código do cargo ou da consulta.
+	OrdemImpressao int                 // Ordem para impressão dos cargos ou consultas no <glossario id='voto-em-transito'>boletim de urna</glossario> e demais relatórios utilizados na Justiça Eleitoral.
+	VotosVotaveis  []TotalVotosVotavel // Informações do total de votos agrupados por tipo de voto e número do <glossario id='votavel'>votável</glossario>.
+}
+
+// Identificador com informações da quantidade de votos agrupados por tipo de voto e número do <glossario id='votavel'>votável</glossario>.
+type TotalVotosVotavel struct {
+	TipoVoto             asn1.Enumerated      `asn1:"tag:1"`          // Tipo do voto.
+	QuantidadeVotos      int                  `asn1:"tag:2"`          // Quantidade de votos por tipo e número do votável.
+	IdentificacaoVotavel IdentificacaoVotavel `asn1:"tag:3,optional"` // Identificação do votável (Para tipo de voto "Branco" ou "Nulo" esse campo deverá ser omitido).
+	Assinatura           []byte               // Assinatura dos dados compostos de votos do votável. Os seguintes campos são assinados: TotalVotosCargo::codigoCargo TotalVotosVotavel::tipoVoto TotalVotosVotavel::quantidadeVotos identificacaoVotavel::codigo identificacaoVotavel::partido Carga::codigoCarga
+}
+
+type QuantidadeEscolhas int // Quantidade máxima de escolhas para um mesmo cargo.
+
+type VotoDigitado string // Digitação como feita pelo eleitor na urna.
+
+// Origem dos votos inseridos no SA.
+type OrigemVotosSA byte
+
+const (
+	Cedula OrigemVotosSA = 1
+	Rdv    OrigemVotosSA = 2
+	Bu     OrigemVotosSA = 3
+)
+
+// Value of the contents octets of an implicitly tagged OrigemVotosSA.
+func OrigemVotosSAFromData(data []byte) (OrigemVotosSA, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return OrigemVotosSAFromEnumerated(e)
+}
+
+func OrigemVotosSAFromEnumerated(e asn1.Enumerated) (OrigemVotosSA, error) {
+	switch e {
+	case 1, 2, 3:
+		return OrigemVotosSA(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v OrigemVotosSA) String() string {
+	if v >= 1 && v <= 3 {
+		return [...]string{"Cedula", "Rdv", "Bu"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Tipo do sistema eleitoral.
+type TipoCedulaSA byte
+
+const (
+	CedulaSAMajoritario  TipoCedulaSA = 1
+	CedulaSAProporcional TipoCedulaSA = 2
+)
+
+// Value of the contents octets of an implicitly tagged TipoCedulaSA.
+func TipoCedulaSAFromData(data []byte) (TipoCedulaSA, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoCedulaSAFromEnumerated(e)
+}
+
+func TipoCedulaSAFromEnumerated(e asn1.Enumerated) (TipoCedulaSA, error) {
+	switch e {
+	case 1, 2:
+		return TipoCedulaSA(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoCedulaSA) String() string {
+	if v >= 1 && v <= 2 {
+		return [...]string{"Majoritario", "Proporcional"}[v-1]
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+type TipoVotoRdv byte
+
+const (
+	LegendaRdv                            TipoVotoRdv = 1
+	NominalRdv                            TipoVotoRdv = 2
+	BrancoRdv                             TipoVotoRdv = 3
+	NuloRdv                               TipoVotoRdv = 4
+	BrancoAposSuspensaoRdv                TipoVotoRdv = 5
+	NuloAposSuspensaoRdv                  TipoVotoRdv = 6
+	NuloPorRepeticaoRdv                   TipoVotoRdv = 7
+	NuloCargoSemCandidatoRdv              TipoVotoRdv = 8
+	NuloAposSuspensaoCargoSemCandidatoRdv TipoVotoRdv = 9
+	TipoVotoInvalidoRdv                   TipoVotoRdv = 255
+)
+
+// Value of the contents octets of an implicitly tagged TipoVotoRdv.
+func TipoVotoRdvFromData(data []byte) (TipoVotoRdv, error) {
+	if len(data) == 0 || len(data) > 4 {
+		return 0, errors.New("invalid data")
+	}
+
+	var e asn1.Enumerated
+	for _, b := range data {
+		e = e<<8 | asn1.Enumerated(b)
+	}
+
+	return TipoVotoRdvFromEnumerated(e)
+}
+
+func TipoVotoRdvFromEnumerated(e asn1.Enumerated) (TipoVotoRdv, error) {
+	switch e {
+	case 1, 2, 3, 4, 5, 6, 7, 8, 9, 255:
+		return TipoVotoRdv(e), nil
+	}
+
+	return 0, errors.New("invalid data")
+}
+
+func (v TipoVotoRdv) String() string {
+	switch v {
+	case LegendaRdv:
+		return "Legenda"
+	case NominalRdv:
+		return "Nominal"
+	case BrancoRdv:
+		return "Branco"
+	case NuloRdv:
+		return "Nulo"
+	case BrancoAposSuspensaoRdv:
+		return "Branco apos suspensao"
+	case NuloAposSuspensaoRdv:
+		return "Nulo apos suspensao"
+	case NuloPorRepeticaoRdv:
+		return "Nulo por repeticao"
+	case NuloCargoSemCandidatoRdv:
+		return "Nulo cargo sem candidato"
+	case NuloAposSuspensaoCargoSemCandidatoRdv:
+		return "Nulo apos suspensao cargo sem candidato"
+	case TipoVotoInvalidoRdv:
+		return "Invalido"
+	}
+
+	return fmt.Sprintf("%T(%d)", v, v)
+}
+
+// Entidade usada para a geração do RDV na memória de resultado.
+type EntidadeResultadoRDV struct {
+	Cabecalho CabecalhoEntidade           // Informações do cabeçalho da entidade.
+	Urna      Urna                        // Informações da urna eletrônica.
+	Rdv       EntidadeRegistroDigitalVoto // Registro digital do voto.
+}
+
+// Entidade usada para o armazenamento do RDV nas mídias interna e externa da urna.
+type EntidadeRegistroDigitalVoto struct {
+	Pleito        IDPleito                    // Identificador do pleito corrente.
+	Fase          asn1.Enumerated             // Fase em que foi gerado o arquivo.
+	Identificacao IdentificacaoSecaoEleitoral // Identificação da seção eleitoral.
+	Eleicoes      asn1.RawValue               // Grupo de votos de todas as eleições.
+}
+
+// Grupo de votos de todas as eleições, da urna ou do SA.
+// Result is one of ([]EleicaoVota, []EleicaoSA)
+func readEleicoes(raw asn1.RawValue) (any, error) {
+	switch raw.Tag {
+	case 0:
+		var v []EleicaoVota
+		err := FillSlice(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	case 1:
+		var v []EleicaoSA
+		err := FillSlice(raw.Bytes, &v)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	return nil, errors.New("could not read Eleicoes")
+}
+
+// Votos para todos os cargos de uma eleição.
+type EleicaoVota struct {
+	IdEleicao   int          // Identificador da eleição.
+	VotosCargos []VotosCargo // Grupo de cédulas da eleição.
+}
+
+// Votos para todos os cargos de uma eleição.
+type EleicaoSA struct {
+	IdEleicao     int             // Identificador da eleição.
+	TipoCedulaSA  asn1.Enumerated // Tipo da cédula de papel apurada pelo SA.
+	OrigemVotosSA asn1.Enumerated // A origem dos votos inseridos no SA.
+	VotosCargos   []VotosCargo    // Grupo de cédulas da eleição.
+}
+
+// Votos de um eleitor para todas as escolhas de um cargo.
+type Voto struct {
+	TipoVoto  asn1.Enumerated // Tipo do voto registrado.
+	Digitacao VotoDigitado    `asn1:"optional,numeric"` // Número como digitado pelo eleitor (não existe para TipoVoto = 3, 5, 6, 8 e 9).
+}
+
+// Todos os votos para um cargo específico.
+type VotosCargo struct {
+	IdCargo            asn1.RawValue      // Código do cargo votado.
+	QuantidadeEscolhas QuantidadeEscolhas // Quantidade de escolhas para o cargo.
+	Votos              []Voto             // Votos do cargo.
+}
@@ -0,0 +1,59 @@
+package ue
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEnumString(t *testing.T) {
+	// 0 and N+1 of each ENUMERATED with N values, or a gap where N+1 is taken.
+	for _, v := range []fmt.Stringer{
+		AlgoritmoAssinatura(0), AlgoritmoAssinatura(4),
+		AlgoritmoHash(0), AlgoritmoHash(5),
+		ModeloUrna(0), ModeloUrna(7),
+		CargoConstitucional(0), CargoConstitucional(14),
+		Fase(0), Fase(4),
+		MotivoApuracaoEletronica(0), MotivoApuracaoEletronica(5),
+		MotivoApuracaoManual(0), MotivoApuracaoManual(5),
+		MotivoApuracaoMistaComBU(0), MotivoApuracaoMistaComBU(7),
+		MotivoApuracaoMistaComMR(0), MotivoApuracaoMistaComMR(8),
+		TipoApuracao(0), TipoApuracao(6),
+		TipoArquivo(0), TipoArquivo(7),
+		TipoCargoConsulta(0), TipoCargoConsulta(4),
+		TipoEnvelope(0), TipoEnvelope(3),
+		TipoUrna(0), TipoUrna(5),
+		TipoVoto(0), TipoVoto(6),
+		OrigemVotosSA(0), OrigemVotosSA(4),
+		TipoCedulaSA(0), TipoCedulaSA(3),
+		TipoVotoRdv(0), TipoVotoRdv(11),
+	} {
+		if s, want := v.String(), fmt.Sprintf("%T(%d)", v, v); s != want {
+			t.Errorf("expected %q, got %q", want, s)
+		}
+	}
+
+	if s := CargoConstitucional(13).String(); s != "Vereador" {
+		t.Error("wrong text", s)
+	}
+	if s := TipoApuracaoNormal.String(); s != "Normal" {
+		t.Error("wrong text", s)
+	}
+	if s := OutrosApMisBu.String(); s != "Outro" {
+		t.Error("wrong text", s)
+	}
+}
+
+func TestEnumFromData(t *testing.T) {
+	if c, err := CargoConstitucionalFromData([]byte{0x0d}); err != nil || c != Vereador {
+		t.Error("expected Vereador", c, err)
+	}
+	if _, err := CargoConstitucionalFromData([]byte{0x01, 0x01}); err == nil {
+		t.Error("expected error for 257")
+	}
+	if _, err := TipoUrnaFromEnumerated(2); err == nil {
+		t.Error("expected error for 2")
+	}
+	if v, err := TipoVotoRdvFromEnumerated(0xff); err != nil || v != TipoVotoInvalidoRdv {
+		t.Error("expected TipoVotoInvalidoRdv", v, err)
+	}
+}
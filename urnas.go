@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	urna "github.com/mpbertram/urna/ue"
+)
+
+var somenteJustificativa bool
+
+// One `bu urnas` record: an urna identified by one of its envelopes.
+type urnaRecord struct {
+	Identificacao string `json:"identificacao"` // Secao, Contingencia or MesaJustificativa.
+	Arquivo       string `json:"arquivo"`
+	Envelope      string `json:"envelope"`
+	Uf            string `json:"uf"`
+	Municipio     string `json:"municipio"`
+	Zona          int    `json:"zona"`
+	Local         int    `json:"local,omitempty"`
+	Secao         int    `json:"secao,omitempty"`
+	Mesa          int    `json:"mesa,omitempty"`
+	Urna          int    `json:"urna,omitempty"`
+}
+
+func newUrnaRecord(u urna.UrnaEnvelope) urnaRecord {
+	r := urnaRecord{
+		Arquivo:   u.Filename,
+		Envelope:  u.TipoEnvelope.String(),
+		Uf:        u.Municipio.Uf,
+		Municipio: u.Municipio.Nome,
+		Zona:      int(u.Identificacao.GetMunicipioZona().Zona),
+	}
+
+	switch i := u.Identificacao.(type) {
+	case urna.IdentificacaoSecaoEleitoral:
+		r.Identificacao = "Secao"
+		r.Local, r.Secao = int(i.Local), int(i.Secao)
+	case urna.IdentificacaoContingencia:
+		r.Identificacao = "Contingencia"
+	case urna.IdentificacaoMesaJustificativa:
+		r.Identificacao = "MesaJustificativa"
+		r.Mesa, r.Urna = int(i.Mesa), int(i.Urna)
+	}
+
+	return r
+}
+
+// Lists the urnas of the envelopes in `files`: voting seções first, then the
+// mesas receptoras de justificativa.
+func listUrnas(files []string) {
+	var secoes, justificativas []urna.UrnaEnvelope
+	add := func(urnas ...urna.UrnaEnvelope) {
+		for _, u := range urnas {
+			if u.IsMesaJustificativa() {
+				justificativas = append(justificativas, u)
+			} else if !somenteJustificativa {
+				secoes = append(secoes, u)
+			}
+		}
+	}
+
+	forEachFile(files, func(f string) {
+		if strings.HasSuffix(f, ".zip") {
+			urnas, err := urna.ListUrnasZip(f)
+			if err != nil {
+				inputError(err)
+			}
+			add(urnas...)
+		}
+
+		if strings.HasSuffix(f, ".bu") {
+			u, err := urna.ReadUrnaEnvelope(f)
+			if err != nil {
+				inputError(err)
+				return
+			}
+			add(u)
+		}
+	})
+
+	w := newRecordWriter("csv",
+		[]string{
+			"Identificacao",
+			"Arquivo",
+			"Envelope",
+			"UF",
+			"Municipio",
+			"Zona",
+			"Local",
+			"Secao",
+			"Mesa",
+			"Urna"})
+
+	for _, u := range append(secoes, justificativas...) {
+		r := newUrnaRecord(u)
+		w.Write(r, []string{
+			r.Identificacao,
+			r.Arquivo,
+			r.Envelope,
+			r.Uf,
+			r.Municipio,
+			fmt.Sprint(r.Zona),
+			blankIfZero(r.Local),
+			blankIfZero(r.Secao),
+			blankIfZero(r.Mesa),
+			blankIfZero(r.Urna)})
+	}
+
+	w.Close()
+}
+
+func urnasBuFlags() []string {
+	urnasFlags := flag.NewFlagSet("urnas", flag.ContinueOnError)
+	urnasFlags.BoolVar(&somenteJustificativa, "justificativa", false, "Only mesas receptoras de justificativa")
+
+	err := urnasFlags.Parse(os.Args[3:])
+	if err != nil {
+		os.Exit(exitInputError)
+	}
+
+	if len(urnasFlags.Args()) == 0 {
+		fmt.Println("usage: urna bu urnas [-justificativa] <file_1> ... <file_n>")
+		urnasFlags.PrintDefaults()
+		os.Exit(exitInputError)
+	}
+
+	return urnasFlags.Args()
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
 	"log"
 	"os"
@@ -25,15 +24,17 @@ func Vscmr() {
 		parseCerts(GetFlags())
 	case "export":
 		exportCerts(GetFlags())
+	case "inventory":
+		inventoryCerts(GetFlags())
 	default:
-		fmt.Println("usage: urna vscmr <verify|csv|cert|export> <file_1> ... <file_n>")
+		fmt.Println("usage: urna vscmr <verify|csv|cert|export|inventory> <file_1> ... <file_n>")
+		fmt.Printf("provided function '%s' is none of (verify, csv, cert, export, inventory)\n", function)
+		setExitCode(exitInputError)
 	}
 }
 
 func exportCerts(files []string) {
-	for _, f := range files {
-		log.Printf("processing file %s", f)
-
+	forEachFile(files, func(f string) {
 		if strings.HasSuffix(f, ".zip") {
 			urna.ExportCertsZip(f)
 		}
@@ -41,84 +42,137 @@ func exportCerts(files []string) {
 		if strings.HasSuffix(f, ".vscmr") {
 			urna.ExportCertsVscmr(f)
 		}
-	}
+	})
 }
 
-func parseCerts(files []string) {
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+func inventoryCerts(files []string) {
+	var infos []urna.CertificadoInfo
 
+	forEachFile(files, func(f string) {
 		if strings.HasSuffix(f, ".zip") {
-			results := urna.VerifyCertsZip(f)
-			if len(results) > 0 {
-				for _, r := range results {
-					print(r)
-				}
-			}
+			infos = append(infos, urna.InventoryZip(f)...)
 		}
 
 		if strings.HasSuffix(f, ".vscmr") {
-			results := urna.VerifyCertsVscmr(f)
-			if len(results) > 0 {
-				for _, r := range results {
-					print(r)
-				}
-			}
+			infos = append(infos, urna.InventoryVscmr(f)...)
 		}
-	}
-}
+	})
 
-func vscmrToCsv(files []string) {
-	w := csv.NewWriter(os.Stdout)
-	w.Write(
+	urna.FlagReuse(infos)
+
+	w := newRecordWriter("csv",
 		[]string{
 			"Municipio",
 			"Zona",
 			"Secao",
 			"Arquivo",
-			"Tipo",
-			"Status",
+			"Modelo urna",
+			"Assinatura",
+			"Nome usuario",
+			"Serial usuario",
+			"Conjunto chave",
+			"Subject",
+			"Serial",
+			"Issuer",
+			"Valido de",
+			"Valido ate",
+			"Fingerprint chave",
+			"Fingerprint certificado",
+			"Chave reutilizada",
+			"Certificado reutilizado",
 			"Erro"})
 
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+	for _, i := range infos {
+		w.Write(i, []string{
+			i.Municipio,
+			i.Zona,
+			i.Secao,
+			i.Filename,
+			i.ModeloUrna,
+			i.Assinatura,
+			i.NomeUsuario,
+			fmt.Sprint(i.SerialUsuario),
+			i.ConjuntoChave,
+			i.Subject,
+			i.Serial,
+			i.Issuer,
+			i.NotBefore,
+			i.NotAfter,
+			i.FingerprintChave,
+			i.FingerprintCert,
+			fmt.Sprint(i.ChaveReutilizada),
+			fmt.Sprint(i.CertReutilizado),
+			i.ErroCertificado})
+	}
+
+	w.Close()
+}
+
+func parseCerts(files []string) {
+	output, done := newVerificationOutput("")
 
+	forEachFile(files, func(f string) {
 		if strings.HasSuffix(f, ".zip") {
-			result := urna.VerifyAssinaturaZip(f)
-			for _, r := range result {
-				writeToCsv(r, w)
-			}
+			output(urna.VerifyCertsZip(f), localForFilename(f, 0))
 		}
 
 		if strings.HasSuffix(f, ".vscmr") {
-			result := urna.VerifyAssinaturaVscmr(f)
-			for _, r := range result {
-				writeToCsv(r, w)
-			}
+			output(urna.VerifyCertsVscmr(f), localForFilename(f, 0))
 		}
-	}
+	})
+
+	done()
+}
 
-	w.Flush()
+func vscmrToCsv(files []string) {
+	output, done := newVerificationOutput("csv")
+	verifyAssinatura(files, output)
+	done()
 }
 
 func verifyVscmr(files []string) {
-	for _, f := range files {
-		log.Printf("processing file %s", f)
+	output, done := newVerificationOutput("")
+	verifyAssinatura(files, output)
+	done()
+}
 
+func verifyAssinatura(files []string, output verificationOutput) {
+	forEachFile(files, func(f string) {
 		if strings.HasSuffix(f, ".zip") {
-			result := urna.VerifyAssinaturaZip(f)
-			for _, r := range result {
-				print(r)
-			}
+			output(urna.VerifyAssinaturaZip(f), localForFilename(f, 0))
 		}
 
 		if strings.HasSuffix(f, ".vscmr") {
-			result := urna.VerifyAssinaturaVscmr(f)
-			for _, r := range result {
+			output(urna.VerifyAssinaturaVscmr(f), localForFilename(f, 0))
+		}
+	})
+}
+
+// Emits the verification results of a seção held at `local`.
+type verificationOutput func(results []urna.VerificationResult, local localRecord)
+
+// Returns functions emitting verification results and terminating the output.
+// Without a format (neither `defaultFormat` nor `-format`), results are logged.
+func newVerificationOutput(defaultFormat string) (verificationOutput, func()) {
+	if len(format) == 0 && len(defaultFormat) == 0 {
+		return func(results []urna.VerificationResult, _ localRecord) {
+			trackResults(results)
+
+			for _, r := range results {
 				print(r)
 			}
-		}
+		}, func() {}
 	}
+
+	w := newRecordWriter(defaultFormat, append(verificationHeader, localHeader()...))
+	return func(results []urna.VerificationResult, local localRecord) {
+		trackResults(results)
+
+		for _, r := range results {
+			w.Write(verificationRecord{r, local}, append(verificationRow(r), local.row()...))
+		}
+		w.Flush()
+	}, w.Close
 }
 
 func print(r urna.VerificationResult) {
@@ -128,35 +182,13 @@ func print(r urna.VerificationResult) {
 	}
 }
 
-func writeToCsv(r urna.VerificationResult, w *csv.Writer) {
-	if r.Err != nil {
-		w.Write([]string{
-			r.Municipio,
-			r.Zona,
-			r.Secao,
-			r.Filename,
-			r.Type.String(),
-			r.Ok.String(),
-			r.Err.Error()})
-	} else {
-		w.Write([]string{
-			r.Municipio,
-			r.Zona,
-			r.Secao,
-			r.Filename,
-			r.Type.String(),
-			r.Ok.String(),
-			""})
-	}
-}
-
 func GetFlags() []string {
 	if len(os.Args) > 3 {
 		return os.Args[3:]
 	}
 
-	fmt.Println("usage: urna vscmr <verify|csv|cert> <file_1> ... <file_n>")
-	os.Exit(1)
+	fmt.Println("usage: urna vscmr <verify|csv|cert|export|inventory> <file_1> ... <file_n>")
+	os.Exit(exitInputError)
 
 	return []string{}
 }